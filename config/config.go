@@ -2,12 +2,26 @@ package config
 
 import (
 	"errors"
+	"time"
 
 	"github.com/cyverse-de/go-mod/cfg"
 )
 
 var ServiceName = "qms"
 
+// DefaultReservationLifetime is the amount of time that a reservation is held if no expiration time is requested.
+var DefaultReservationLifetime = time.Hour
+
+// DefaultOutboxSink is the type of sink that outbox events are delivered to if no sink type is configured.
+var DefaultOutboxSink = "log"
+
+// DefaultUsageBatchLimit is the maximum number of usage updates that may be included in a single batch request if no
+// limit is configured.
+var DefaultUsageBatchLimit = 50000
+
+// DefaultOutboxInterval is the amount of time to wait between outbox event deliveries if no interval is configured.
+var DefaultOutboxInterval = 10 * time.Second
+
 // Specification defines the configuration settings for the qms service.
 type Specification struct {
 	DatabaseURI         string
@@ -17,6 +31,13 @@ type Specification struct {
 	ConfigPath          string
 	EnvPrefix           string
 	UsernameSuffix      string
+	ReportOverages      bool
+	ReservationLifetime time.Duration
+	UsageBatchLimit     int
+	OutboxSink          string
+	OutboxWebhookURL    string
+	OutboxLogPath       string
+	OutboxInterval      time.Duration
 }
 
 // LoadConfig loads the configuration for the qms service.
@@ -47,5 +68,38 @@ func LoadConfig(envPrefix, configPath, dotEnvPath string) (*Specification, error
 		return nil, errors.New("username.suffix or QMS_USERNAME_SUFFIX must be set")
 	}
 
+	s.ReportOverages = k.Bool("report.overages")
+
+	s.ReservationLifetime = DefaultReservationLifetime
+	if k.Exists("reservations.lifetime") {
+		s.ReservationLifetime = k.Duration("reservations.lifetime")
+		if s.ReservationLifetime <= 0 {
+			return nil, errors.New("reservations.lifetime or QMS_RESERVATIONS_LIFETIME must be a positive duration")
+		}
+	}
+
+	s.UsageBatchLimit = DefaultUsageBatchLimit
+	if k.Exists("usages.batch.limit") {
+		s.UsageBatchLimit = k.Int("usages.batch.limit")
+		if s.UsageBatchLimit <= 0 {
+			return nil, errors.New("usages.batch.limit or QMS_USAGES_BATCH_LIMIT must be a positive integer")
+		}
+	}
+
+	s.OutboxSink = k.String("outbox.sink")
+	if s.OutboxSink == "" {
+		s.OutboxSink = DefaultOutboxSink
+	}
+	s.OutboxWebhookURL = k.String("outbox.webhook.url")
+	s.OutboxLogPath = k.String("outbox.log.path")
+
+	s.OutboxInterval = DefaultOutboxInterval
+	if k.Exists("outbox.interval") {
+		s.OutboxInterval = k.Duration("outbox.interval")
+		if s.OutboxInterval <= 0 {
+			return nil, errors.New("outbox.interval or QMS_OUTBOX_INTERVAL must be a positive duration")
+		}
+	}
+
 	return &s, err
 }
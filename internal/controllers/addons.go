@@ -0,0 +1,490 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cyverse-de/echo-middleware/v2/params"
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/httpmodel"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// extractAddonID extracts and validates the addon ID path parameter.
+func extractAddonID(ctx echo.Context) (string, error) {
+	addonID, err := params.ValidatedPathParam(ctx, "addon_id", "uuid_rfc4122")
+	if err != nil {
+		return "", fmt.Errorf("the addon ID must be a valid UUID")
+	}
+	return addonID, nil
+}
+
+// ListAddons is the handler for the GET /v1/addons endpoint.
+//
+// swagger:route GET /v1/addons addons listAddons
+//
+// # List Addons
+//
+// Lists all of the addons that are currently available.
+//
+// responses:
+//
+//	200: addonsResponse
+//	500: internalServerErrorResponse
+func (s Server) ListAddons(ctx echo.Context) error {
+	log := log.WithFields(logrus.Fields{"context": "listing addons"})
+
+	context := ctx.Request().Context()
+
+	addons, err := db.ListAddons(context, s.GORMDB)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+
+	log.Debug("listing addons from the database")
+
+	return model.Success(ctx, addons, http.StatusOK)
+}
+
+// GetAddonByID returns the addon with the given identifier.
+//
+// swagger:route GET /v1/addons/{addon_id} addons getAddonByID
+//
+// # Get Addon Information
+//
+// Returns the addon with the given identifier.
+//
+// responses:
+//
+//	200: addonResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) GetAddonByID(ctx echo.Context) error {
+	var err error
+
+	log := log.WithFields(logrus.Fields{"context": "getting addon by id"})
+
+	context := ctx.Request().Context()
+
+	// Extract and validate the addon ID.
+	addonID, err := extractAddonID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	log = log.WithFields(logrus.Fields{"addonID": addonID})
+	log.Debug("extracted and validated the addon ID from the request")
+
+	// Look up the addon.
+	addon, err := db.GetAddonByID(context, s.GORMDB, addonID)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+	if addon == nil {
+		msg := fmt.Sprintf("addon ID %s not found", addonID)
+		return model.Error(ctx, msg, http.StatusNotFound)
+	}
+
+	log.Debug("successfully looked up addon to return")
+
+	return model.Success(ctx, addon, http.StatusOK)
+}
+
+// AddAddon adds a new addon to the database.
+//
+// swagger:route POST /v1/addons addons addAddon
+//
+// # Add Addon
+//
+// Adds a new addon to the database.
+//
+// Responses:
+//
+//	200: addonResponse
+//	400: badRequestResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) AddAddon(ctx echo.Context) error {
+	var err error
+
+	log := log.WithFields(logrus.Fields{"context": "adding addon"})
+
+	context := ctx.Request().Context()
+
+	// Parse and validate the request body.
+	var addon httpmodel.NewAddon
+	if err = ctx.Bind(&addon); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	if err = addon.Validate(); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	log = log.WithFields(logrus.Fields{"addon": addon.Name})
+	log.Debugf("adding a new addon to the database: %+v", addon)
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		dbAddon := addon.ToDBModel()
+
+		// Make sure that an addon with the same name doesn't already exist.
+		existingAddon, err := db.GetAddonByName(context, tx, addon.Name)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if existingAddon != nil {
+			msg := fmt.Sprintf("an addon named `%s` already exists", addon.Name)
+			return model.Error(ctx, msg, http.StatusConflict)
+		}
+
+		// Look up the resource type.
+		resourceType, err := db.GetResourceTypeByName(context, tx, addon.ResourceType.Name)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if resourceType == nil {
+			msg := fmt.Sprintf("resource type not found: %s", addon.ResourceType.Name)
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+		dbAddon.ResourceType = *resourceType
+		dbAddon.ResourceTypeID = resourceType.ID
+
+		// Add the addon to the database.
+		err = tx.WithContext(context).Omit("ResourceType").Create(&dbAddon).Error
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		log.Debug("successfully added addon to the database")
+
+		// Look up the addon with all of its details and return it in the response.
+		result, err := db.GetAddonByID(context, tx, *dbAddon.ID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if result == nil {
+			msg := fmt.Sprintf("addon ID %s not found after saving it", *dbAddon.ID)
+			return model.Error(ctx, msg, http.StatusInternalServerError)
+		}
+		return model.Success(ctx, result, http.StatusOK)
+	})
+}
+
+// UpdateAddon updates an existing addon.
+//
+// swagger:route PUT /v1/addons/{addon_id} addons updateAddon
+//
+// # Update Addon
+//
+// Updates the name, description, resource type, and defaults of an existing addon. The addon rates are not modified
+// by this endpoint.
+//
+// Responses:
+//
+//	200: addonResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) UpdateAddon(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the addon ID.
+	addonID, err := extractAddonID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger and log a message indicating that the addon is being updated.
+	log := log.WithFields(
+		logrus.Fields{
+			"context":  "updating addon",
+			"addon_id": addonID,
+		},
+	)
+	log.Info("updating an existing addon")
+
+	// Parse and validate the request body.
+	var updatedAddon httpmodel.UpdatedAddon
+	if err = ctx.Bind(&updatedAddon); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	if err = updatedAddon.Validate(); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the addon exists.
+		addon, err := db.GetAddonByID(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if addon == nil {
+			msg := fmt.Sprintf("addon ID %s not found", addonID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Verify that a different addon with the new name doesn't exist already.
+		homonym, err := db.GetAddonByName(context, tx, updatedAddon.Name)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if homonym != nil && *homonym.ID != *addon.ID {
+			msg := fmt.Sprintf("an addon named `%s` already exists", updatedAddon.Name)
+			return model.Error(ctx, msg, http.StatusConflict)
+		}
+
+		// Look up the resource type.
+		resourceType, err := db.GetResourceTypeByName(context, tx, updatedAddon.ResourceType.Name)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if resourceType == nil {
+			msg := fmt.Sprintf("resource type not found: %s", updatedAddon.ResourceType.Name)
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Update the addon.
+		addon.Name = updatedAddon.Name
+		addon.Description = updatedAddon.Description
+		addon.ResourceTypeID = resourceType.ID
+		addon.DefaultAmount = updatedAddon.DefaultAmount
+		addon.DefaultPaid = *updatedAddon.DefaultPaid
+		err = db.UpdateAddon(context, tx, addon)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Look up the addon with the updates included and return it in the response.
+		addon, err = db.GetAddonByID(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if addon == nil {
+			msg := fmt.Sprintf("addon ID %s not found after saving it", addonID)
+			return model.Error(ctx, msg, http.StatusInternalServerError)
+		}
+		return model.Success(ctx, addon, http.StatusOK)
+	})
+}
+
+// DeleteAddon removes an addon from the database.
+//
+// swagger:route DELETE /v1/addons/{addon_id} addons deleteAddon
+//
+// # Delete Addon
+//
+// Removes an addon and its rates from the database. Addons that have already been applied to subscriptions cannot be
+// deleted.
+//
+// Responses:
+//
+//	200: successMessageResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) DeleteAddon(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the addon ID.
+	addonID, err := extractAddonID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger and log a message indicating that the addon is being deleted.
+	log := log.WithFields(
+		logrus.Fields{
+			"context":  "deleting addon",
+			"addon_id": addonID,
+		},
+	)
+	log.Info("deleting an existing addon")
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the addon exists.
+		exists, err := db.CheckAddonExistence(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if !exists {
+			msg := fmt.Sprintf("addon ID %s not found", addonID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Deleting an addon that has been applied to a subscription would cascade to the subscription addons.
+		inUse, err := db.CheckAddonUsage(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if inUse {
+			msg := fmt.Sprintf("addon ID %s has been applied to at least one subscription", addonID)
+			return model.Error(ctx, msg, http.StatusConflict)
+		}
+
+		// Delete the addon.
+		err = db.DeleteAddon(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		return model.SuccessMessage(ctx, "Success", http.StatusOK)
+	})
+}
+
+// GetActiveAddonRate reports the active rate for an existing addon.
+//
+// swagger:route GET /v1/addons/{addon_id}/active-rate addons getAddonActiveRate
+//
+// # Get Active Rate for an Addon
+//
+// Returns the active rate for an addon. The active addon rate is the rate associated with the selected addon with the
+// most recent effective date that is before the current date.
+//
+// Responses:
+//
+//	200: activeAddonRateResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) GetActiveAddonRate(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the addon ID.
+	addonID, err := extractAddonID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger and log a message indicating what is being done.
+	log := log.WithFields(
+		logrus.Fields{
+			"context":  "getting active addon rate",
+			"addon_id": addonID,
+		},
+	)
+	log.Info("getting the active rate for an addon")
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the addon exists.
+		exists, err := db.CheckAddonExistence(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if !exists {
+			msg := fmt.Sprintf("addon ID %s not found", addonID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Look up the active addon rate.
+		activeAddonRate, err := db.GetActiveAddonRate(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if activeAddonRate == nil {
+			msg := fmt.Sprintf("no active rate found for addon ID %s", addonID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		return model.Success(ctx, activeAddonRate, http.StatusOK)
+	})
+}
+
+// AddAddonRates adds rates to an existing addon.
+//
+// swagger:route POST /v1/addons/{addon_id}/rates addons addAddonRates
+//
+// # Add Addon Rates
+//
+// Adds rates to an existing addon. The existing rates for the addon will be left in place. The effective rate for a
+// specific addon is always the rate with the most recent effective date before the current date.
+//
+// Responses:
+//
+//	200: addonResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) AddAddonRates(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the addon ID.
+	addonID, err := extractAddonID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger and log a message indicating that the addon is being updated.
+	log := log.WithFields(
+		logrus.Fields{
+			"context":  "adding addon rates",
+			"addon_id": addonID,
+		},
+	)
+	log.Info("adding rates to an existing addon")
+
+	// Parse and validate the request body.
+	var addonRateList httpmodel.NewAddonRateList
+	if err = ctx.Bind(&addonRateList); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	if err = addonRateList.Validate(); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the addon exists.
+		addon, err := db.GetAddonByID(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if addon == nil {
+			msg := fmt.Sprintf("addon ID %s not found", addonID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Convert the list of addon rates to the corresponding DB model.
+		addonRates := addonRateList.ToDBModel()
+
+		// Verify that none of the incoming addon rates duplicate existing addon rates.
+		existingAddonRates := make(map[int64]bool)
+		for _, ar := range addon.AddonRates {
+			existingAddonRates[ar.EffectiveDate.UnixMilli()] = true
+		}
+		for _, ar := range addonRates {
+			if existingAddonRates[ar.EffectiveDate.UnixMilli()] {
+				msg := fmt.Sprintf("addon rate with effective date %s already exists", ar.EffectiveDate)
+				return model.Error(ctx, msg, http.StatusBadRequest)
+			}
+		}
+
+		// Plug the addon ID into each of the addon rates.
+		for i := range addonRates {
+			addonRates[i].AddonID = &addonID
+		}
+
+		// Save the list of addon rates.
+		err = db.SaveAddonRates(context, tx, addonRates)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Look up the addon with the new addon rates included and return it in the response.
+		addon, err = db.GetAddonByID(context, tx, addonID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if addon == nil {
+			msg := fmt.Sprintf("addon ID %s not found after saving it", addonID)
+			return model.Error(ctx, msg, http.StatusInternalServerError)
+		}
+		return model.Success(ctx, addon, http.StatusOK)
+	})
+}
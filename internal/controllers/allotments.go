@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ListSubscriptionPeriods lists the completed allotment periods of a subscription.
+//
+// swagger:route GET /v1/subscriptions/{subscription_id}/periods subscriptions listSubscriptionPeriods
+//
+// # List Subscription Periods
+//
+// Lists the completed allotment periods of a subscription in allotment mode, oldest first. Each entry contains the
+// final quota and usage values for one consumable resource type in one period. The quota and usage values for the
+// current period are included in the subscription details. Subscriptions that aren't in allotment mode have no periods.
+//
+// Responses:
+//
+//	200: subscriptionPeriodListingResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) ListSubscriptionPeriods(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the subscription ID.
+	subscriptionID, err := extractSubscriptionID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "listing subscription periods", "subscription_id": subscriptionID})
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the subscription exists.
+		_, err := db.GetSubscriptionDetails(context, tx, subscriptionID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			msg := fmt.Sprintf("subscription ID %s not found", subscriptionID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		} else if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// List the periods.
+		periods, err := db.ListSubscriptionPeriods(context, tx, subscriptionID)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		return model.Success(ctx, periods, http.StatusOK)
+	})
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/ledger"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// DefaultForecastWindowDays is the number of days of usage updates used to compute consumption rates if no window is
+// specified.
+const DefaultForecastWindowDays = 30
+
+// reversedUpdateIDs returns the identifiers of the updates reversed by REVERSE updates in the given list, excluding
+// updates that are already in the list.
+func reversedUpdateIDs(updates []model.Update) []string {
+	present := make(map[string]bool)
+	for _, update := range updates {
+		if update.ID != nil {
+			present[*update.ID] = true
+		}
+	}
+
+	var updateIDs []string
+	for _, update := range updates {
+		if update.UpdateOperation.Name != model.UpdateOperationReverse || update.OriginalUpdateID == nil {
+			continue
+		}
+		if !present[*update.OriginalUpdateID] {
+			present[*update.OriginalUpdateID] = true
+			updateIDs = append(updateIDs, *update.OriginalUpdateID)
+		}
+	}
+
+	return updateIDs
+}
+
+// GetUsageForecast projects when a user will run out of each consumable resource.
+//
+// swagger:route GET /v1/users/{username}/forecast users getUsageForecast
+//
+// # Get a Usage Forecast
+//
+// Computes the recent consumption rate of each consumable resource in the user's active subscription, and projects
+// when the usage will reach the quota if consumption continues at that rate. The consumption rate is the total amount
+// added by usage updates during the forecast window, less any refunds, divided by the length of the window in days.
+// Updates that set the usage directly, and reversals of those updates, don't affect the consumption rate. The forecast
+// window covers the most recent days, but never extends back before the start of the subscription. If the user doesn't
+// have an active subscription then a new subscription for the default subscription plan will be created.
+//
+// Responses:
+//
+//	200: usageForecastResponse
+//	400: badRequestResponse
+//	500: internalServerErrorResponse
+func (s Server) GetUsageForecast(ctx echo.Context) error {
+	var err error
+
+	log := log.WithFields(logrus.Fields{"context": "forecasting usage"})
+	context := ctx.Request().Context()
+
+	// Extract the username.
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		return model.Error(ctx, "invalid username", http.StatusBadRequest)
+	}
+	log = log.WithFields(logrus.Fields{"user": username})
+
+	// Extract the forecast window.
+	var window int32 = DefaultForecastWindowDays
+	window, err = query.ValidateIntQueryParam(ctx, "window", &window, "gte=1")
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	resourceTypeName := ctx.QueryParam("resource-type")
+
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		// Load the user's current subscription, creating a new subscription if necessary.
+		subscription, err := db.GetActiveSubscriptionDetails(context, tx, username)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Determine the forecast window.
+		now := time.Now()
+		windowStart := now.AddDate(0, 0, -int(window))
+		if subscription.EffectiveStartDate != nil && subscription.EffectiveStartDate.After(windowStart) {
+			windowStart = *subscription.EffectiveStartDate
+		}
+		forecast := model.UsageForecast{
+			Username:         username,
+			SubscriptionID:   *subscription.ID,
+			EffectiveEndDate: subscription.EffectiveEndDate,
+			WindowStart:      windowStart,
+			WindowEnd:        now,
+			Resources:        make([]model.ResourceForecast, 0),
+		}
+
+		// Build the forecast for each consumable resource.
+		found := false
+		for i := range subscription.Quotas {
+			quota := &subscription.Quotas[i]
+			if resourceTypeName != "" && quota.ResourceType.Name != resourceTypeName {
+				continue
+			}
+			found = true
+			if !quota.ResourceType.Consumable {
+				continue
+			}
+
+			// Compute the consumption rate.
+			updates, err := db.ListUsageUpdatesForUser(context, tx, username, quota.ResourceTypeID, &windowStart, &now)
+			if err != nil {
+				log.Error(err)
+				return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			}
+
+			// Include the originals of any reversals so that reversals of SET updates can be recognized.
+			originals, err := db.ListUpdatesByID(context, tx, reversedUpdateIDs(updates))
+			if err != nil {
+				log.Error(err)
+				return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			}
+			dailyRate := ledger.DailyRate(append(updates, originals...), windowStart, now)
+
+			forecast.Resources = append(forecast.Resources, ledger.ForecastResource(subscription, quota, dailyRate, now))
+		}
+
+		// The resource type must be in the subscription if one was specified.
+		if resourceTypeName != "" && !found {
+			msg := fmt.Sprintf("the active subscription has no quota for resource type '%s'", resourceTypeName)
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+
+		return model.Success(ctx, forecast, http.StatusOK)
+	})
+}
@@ -0,0 +1,717 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cyverse-de/echo-middleware/v2/params"
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/httpmodel"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// extractOrganizationID extracts and validates the organization ID path parameter.
+func extractOrganizationID(ctx echo.Context) (string, error) {
+	organizationID, err := params.ValidatedPathParam(ctx, "organization_id", "uuid_rfc4122")
+	if err != nil {
+		return "", fmt.Errorf("the organization ID must be a valid UUID")
+	}
+	return organizationID, nil
+}
+
+// getOrganizationMember looks up the membership of the user with the given username in the organization with the given
+// ID. A nil pointer is returned if the user isn't a member of the organization.
+func getOrganizationMember(
+	ctx echo.Context, tx *gorm.DB, organizationID, username string,
+) (*model.OrganizationMember, error) {
+	member, err := db.GetOrganizationMembership(ctx.Request().Context(), tx, username)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || *member.OrganizationID != organizationID {
+		return nil, nil
+	}
+	return member, nil
+}
+
+// ListOrganizations lists all of the organizations.
+//
+// swagger:route GET /v1/organizations organizations listOrganizations
+//
+// # List Organizations
+//
+// Lists all of the organizations, sorted by name.
+//
+// Responses:
+//
+//	200: organizationListingResponse
+//	500: internalServerErrorResponse
+func (s Server) ListOrganizations(ctx echo.Context) error {
+	log := log.WithFields(logrus.Fields{"context": "listing organizations"})
+	context := ctx.Request().Context()
+
+	organizations, err := db.ListOrganizations(context, s.GORMDB)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+
+	return model.Success(ctx, organizations, http.StatusOK)
+}
+
+// AddOrganization adds a new organization.
+//
+// swagger:route POST /v1/organizations organizations addOrganization
+//
+// # Add an Organization
+//
+// Adds a new organization. The organization has no members or subscription when it's first created.
+//
+// Responses:
+//
+//	200: organizationResponse
+//	400: badRequestResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) AddOrganization(ctx echo.Context) error {
+	var err error
+
+	// Parse and validate the request body.
+	var body httpmodel.NewOrganization
+	if err = ctx.Bind(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if err = ctx.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "adding an organization", "organization": body.Name})
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Organization names must be unique.
+		existing, err := db.GetOrganizationByName(context, tx, body.Name)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if existing != nil {
+			msg := fmt.Sprintf("organization '%s' already exists", body.Name)
+			return model.Error(ctx, msg, http.StatusConflict)
+		}
+
+		// Record the organization.
+		organization := model.Organization{
+			Name:        body.Name,
+			Description: body.Description,
+		}
+		err = db.SaveOrganization(context, tx, &organization)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Look up the organization so that the timestamps are included in the response.
+		saved, err := db.GetOrganization(context, tx, *organization.ID)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		return model.Success(ctx, saved, http.StatusOK)
+	})
+}
+
+// GetOrganization returns the details of an organization.
+//
+// swagger:route GET /v1/organizations/{organization_id} organizations getOrganization
+//
+// # Get Organization Details
+//
+// Returns the details of an organization, including its members and their caps.
+//
+// Responses:
+//
+//	200: organizationResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) GetOrganization(ctx echo.Context) error {
+	// Extract and validate the organization ID.
+	organizationID, err := extractOrganizationID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "getting an organization", "organization_id": organizationID})
+	context := ctx.Request().Context()
+
+	// Look up the organization.
+	organization, err := db.GetOrganization(context, s.GORMDB, organizationID)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+	if organization == nil {
+		msg := fmt.Sprintf("organization ID %s not found", organizationID)
+		return model.Error(ctx, msg, http.StatusNotFound)
+	}
+
+	return model.Success(ctx, organization, http.StatusOK)
+}
+
+// PutOrganizationMember adds a user to an organization or changes the user's role in the organization.
+//
+// swagger:route PUT /v1/organizations/{organization_id}/members/{username} organizations putOrganizationMember
+//
+// # Add or Update an Organization Member
+//
+// Adds the user to the organization with the given role, or changes the user's role if the user is already a member.
+// A user may only belong to one organization at a time. While the organization has an active subscription, the
+// member's usage is charged to the organization's pool rather than to the member's personal subscription.
+//
+// Responses:
+//
+//	200: organizationMemberResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) PutOrganizationMember(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the organization ID.
+	organizationID, err := extractOrganizationID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Extract the username.
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		return model.Error(ctx, "invalid username", http.StatusBadRequest)
+	}
+
+	// Parse and validate the request body.
+	var body httpmodel.OrganizationMembership
+	if err = ctx.Bind(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if err = ctx.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{
+		"context":         "adding an organization member",
+		"organization_id": organizationID,
+		"user":            username,
+	})
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the organization exists.
+		organization, err := db.GetOrganization(context, tx, organizationID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if organization == nil {
+			msg := fmt.Sprintf("organization ID %s not found", organizationID)
+			return rollback(ctx, msg, http.StatusNotFound)
+		}
+
+		// Either add the user to the database or look up the existing user information.
+		user, err := db.GetUser(context, tx, username)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Users may only belong to one organization at a time.
+		existing, err := db.GetOrganizationMembershipForUserID(context, tx, *user.ID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if existing != nil && *existing.OrganizationID != organizationID {
+			msg := fmt.Sprintf("user '%s' already belongs to organization '%s'", username, existing.Organization.Name)
+			return rollback(ctx, msg, http.StatusConflict)
+		}
+
+		// Record the membership.
+		member := model.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         user.ID,
+			Role:           body.Role,
+		}
+		err = db.SaveOrganizationMember(context, tx, &member)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debugf("set the member's role to %s", body.Role)
+
+		// Look up the membership with all of its details and return it in the response.
+		saved, err := db.GetOrganizationMembership(context, tx, username)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		return model.Success(ctx, saved, http.StatusOK)
+	})
+	return transactionResult(err)
+}
+
+// DeleteOrganizationMember removes a user from an organization.
+//
+// swagger:route DELETE /v1/organizations/{organization_id}/members/{username} organizations deleteOrganizationMember
+//
+// # Remove an Organization Member
+//
+// Removes the user from the organization along with any caps on the user's consumption. The amounts that the user has
+// already consumed from the organization's pool remain in the pool. Subsequent usage is charged to the user's personal
+// subscription.
+//
+// Responses:
+//
+//	200: organizationMemberResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) DeleteOrganizationMember(ctx echo.Context) error {
+	// Extract and validate the organization ID.
+	organizationID, err := extractOrganizationID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Extract the username.
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		return model.Error(ctx, "invalid username", http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{
+		"context":         "removing an organization member",
+		"organization_id": organizationID,
+		"user":            username,
+	})
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up the membership.
+		member, err := getOrganizationMember(ctx, tx, organizationID, username)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if member == nil {
+			msg := fmt.Sprintf("user '%s' is not a member of organization ID %s", username, organizationID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Remove the membership.
+		err = db.DeleteOrganizationMember(context, tx, *member.ID)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		return model.Success(ctx, member, http.StatusOK)
+	})
+}
+
+// extractMemberCapRequest extracts the common path parameters used by the organization member cap endpoints.
+func (s Server) extractMemberCapRequest(ctx echo.Context) (string, string, string, error) {
+	organizationID, err := extractOrganizationID(ctx)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		return "", "", "", fmt.Errorf("invalid username")
+	}
+
+	resourceTypeName := ctx.Param("resource-type")
+	if resourceTypeName == "" {
+		return "", "", "", fmt.Errorf("no resource type name provided in request")
+	}
+
+	return organizationID, username, resourceTypeName, nil
+}
+
+// PutOrganizationMemberCap limits the amount of a resource that an organization member may consume.
+//
+// swagger:route PUT /v1/organizations/{organization_id}/members/{username}/caps/{resource-type} organizations putOrganizationMemberCap
+//
+// # Set an Organization Member Cap
+//
+// Limits the amount of a resource that the member may consume from the organization's pool. Requests that would take
+// the member's usage of the resource above the cap are denied even if the pool still has room. Caps apply to each
+// period of the organization's subscription, so they reset whenever the pool's usage resets.
+//
+// Responses:
+//
+//	200: organizationMemberResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) PutOrganizationMemberCap(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the path parameters.
+	organizationID, username, resourceTypeName, err := s.extractMemberCapRequest(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Parse and validate the request body.
+	var body httpmodel.OrganizationMemberCap
+	if err = ctx.Bind(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if err = ctx.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{
+		"context":         "setting an organization member cap",
+		"organization_id": organizationID,
+		"user":            username,
+		"resource-type":   resourceTypeName,
+	})
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up the resource type.
+		resourceType, err := db.GetResourceTypeByName(context, tx, resourceTypeName)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if resourceType == nil {
+			msg := fmt.Sprintf("resource type '%s' not found", resourceTypeName)
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Look up the membership.
+		member, err := getOrganizationMember(ctx, tx, organizationID, username)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if member == nil {
+			msg := fmt.Sprintf("user '%s' is not a member of organization ID %s", username, organizationID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Record the cap.
+		memberCap := model.OrganizationMemberCap{
+			OrganizationMemberID: member.ID,
+			ResourceTypeID:       resourceType.ID,
+			Cap:                  body.Cap,
+		}
+		err = db.UpsertOrganizationMemberCap(context, tx, &memberCap)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debugf("set the member's cap to %f %s", body.Cap, resourceType.Unit)
+
+		// Look up the membership with its updated caps and return it in the response.
+		member, err = db.GetOrganizationMembership(context, tx, username)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		return model.Success(ctx, member, http.StatusOK)
+	})
+}
+
+// DeleteOrganizationMemberCap removes the limit on the amount of a resource that an organization member may consume.
+//
+// swagger:route DELETE /v1/organizations/{organization_id}/members/{username}/caps/{resource-type} organizations deleteOrganizationMemberCap
+//
+// # Remove an Organization Member Cap
+//
+// Removes the limit on the amount of a resource that the member may consume. The member may then consume any amount
+// of the resource that remains in the organization's pool.
+//
+// Responses:
+//
+//	200: organizationMemberResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) DeleteOrganizationMemberCap(ctx echo.Context) error {
+	// Extract and validate the path parameters.
+	organizationID, username, resourceTypeName, err := s.extractMemberCapRequest(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{
+		"context":         "removing an organization member cap",
+		"organization_id": organizationID,
+		"user":            username,
+		"resource-type":   resourceTypeName,
+	})
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up the resource type.
+		resourceType, err := db.GetResourceTypeByName(context, tx, resourceTypeName)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if resourceType == nil {
+			msg := fmt.Sprintf("resource type '%s' not found", resourceTypeName)
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Look up the membership.
+		member, err := getOrganizationMember(ctx, tx, organizationID, username)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if member == nil {
+			msg := fmt.Sprintf("user '%s' is not a member of organization ID %s", username, organizationID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Remove the cap.
+		count, err := db.DeleteOrganizationMemberCap(context, tx, *member.ID, *resourceType.ID)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if count == 0 {
+			msg := fmt.Sprintf("user '%s' has no cap for %s", username, resourceTypeName)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Look up the membership with its updated caps and return it in the response.
+		member, err = db.GetOrganizationMembership(context, tx, username)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		return model.Success(ctx, member, http.StatusOK)
+	})
+}
+
+// AddOrganizationSubscription subscribes an organization to a plan.
+//
+// swagger:route POST /v1/organizations/{organization_id}/subscriptions organizations addOrganizationSubscription
+//
+// # Subscribe an Organization to a Plan
+//
+// Subscribes the organization to the plan with the given name. Any of the organization's subscriptions that overlap
+// with the new subscription are deactivated. The quotas in the subscription form a pool that is shared by all of the
+// organization's members. The subscription options have the same meanings and defaults as they do for bulk
+// subscription requests. Organizations may not be subscribed to archived plans.
+//
+// Responses:
+//
+//	200: subscription
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) AddOrganizationSubscription(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the organization ID.
+	organizationID, err := extractOrganizationID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Parse and validate the request body.
+	var body httpmodel.NewOrganizationSubscription
+	if err = ctx.Bind(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if err = ctx.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if body.GetPeriods() < 0 {
+		return model.Error(ctx, "the number of periods may not be negative", http.StatusBadRequest)
+	}
+	startDate := body.GetStartDate()
+	endDate := body.GetEndDate(startDate)
+	if !endDate.After(time.Now()) {
+		return model.Error(ctx, "end date must be in the future", http.StatusBadRequest)
+	}
+	if !startDate.Before(endDate) {
+		return model.Error(ctx, "the start date must precede the end date", http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{
+		"context":         "subscribing an organization to a plan",
+		"organization_id": organizationID,
+		"plan":            body.PlanName,
+		"start-date":      startDate,
+		"end-date":        endDate,
+	})
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the organization exists.
+		organization, err := db.GetOrganization(context, tx, organizationID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if organization == nil {
+			msg := fmt.Sprintf("organization ID %s not found", organizationID)
+			return rollback(ctx, msg, http.StatusNotFound)
+		}
+
+		// Verify that a plan with the given name exists.
+		plan, err := db.GetPlan(context, tx, body.PlanName)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if plan == nil {
+			msg := fmt.Sprintf("plan name `%s` not found", body.PlanName)
+			return rollback(ctx, msg, http.StatusBadRequest)
+		}
+		if plan.Archived {
+			msg := fmt.Sprintf("plan name `%s` has been archived", body.PlanName)
+			return rollback(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Deactivate conflicting subscriptions for the organization.
+		err = db.DeactivateOrganizationSubscriptions(context, tx, organizationID, startDate, endDate)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Subscribe the organization to the plan.
+		subscription, err := db.SubscribeOrganizationToPlan(context, tx, organization, plan, &body.SubscriptionOptions)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debug("finished adding the new subscription")
+
+		// Look up the subscription with all of its details and return it in the response.
+		subscription, err = db.GetSubscriptionDetails(context, tx, *subscription.ID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		return model.Success(ctx, subscription, http.StatusOK)
+	})
+	return transactionResult(err)
+}
+
+// GetOrganizationSubscription returns the details of an organization's active subscription.
+//
+// swagger:route GET /v1/organizations/{organization_id}/subscription organizations getOrganizationSubscription
+//
+// # Get an Organization's Active Subscription
+//
+// Returns the details of the organization's active subscription, along with the amount of each resource that each
+// member has consumed from the organization's pool.
+//
+// Responses:
+//
+//	200: organizationSubscriptionResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) GetOrganizationSubscription(ctx echo.Context) error {
+	// Extract and validate the organization ID.
+	organizationID, err := extractOrganizationID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{
+		"context":         "getting an organization's subscription",
+		"organization_id": organizationID,
+	})
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the organization exists.
+		organization, err := db.GetOrganization(context, tx, organizationID)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if organization == nil {
+			msg := fmt.Sprintf("organization ID %s not found", organizationID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// Look up the active subscription.
+		subscription, err := db.GetActiveOrganizationSubscriptionForDate(context, tx, organizationID, time.Now())
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if subscription == nil {
+			msg := fmt.Sprintf("organization '%s' has no active subscription", organization.Name)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+		subscription, err = db.GetSubscriptionDetails(context, tx, *subscription.ID)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Look up the amounts consumed by each member.
+		memberUsages, err := db.ListOrganizationMemberUsages(context, tx, *subscription.ID)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		result := model.OrganizationSubscription{
+			Subscription: *subscription,
+			MemberUsages: memberUsages,
+		}
+		return model.Success(ctx, result, http.StatusOK)
+	})
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// GetUserOverages is the handler for the GET /v1/users/{username}/overages endpoint.
+//
+// swagger:route GET /v1/users/{username}/overages users getUserOverages
+//
+// # List Overages for a User
+//
+// Lists the resource types for which the user's current usage is at or above the quota in the user's active
+// subscription. An empty list is always returned if overage reporting is disabled.
+//
+// Responses:
+//
+//	200: overagesResponse
+//	400: badRequestResponse
+//	500: internalServerErrorResponse
+func (s Server) GetUserOverages(ctx echo.Context) error {
+	log := log.WithFields(logrus.Fields{"context": "getting user overages"})
+
+	context := ctx.Request().Context()
+
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		return model.Error(ctx, "invalid username", http.StatusBadRequest)
+	}
+
+	log = log.WithFields(logrus.Fields{"user": username})
+
+	// Don't report any overages if overage reporting is disabled.
+	if !s.ReportOverages {
+		log.Debug("overage reporting is disabled")
+		return model.Success(ctx, make([]model.Overage, 0), http.StatusOK)
+	}
+
+	// Start a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		// Look up or create the active subscription.
+		subscription, err := db.GetActiveSubscriptionDetails(context, tx, username)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		log.Debug("found the active subscription")
+
+		return model.Success(ctx, subscription.GetOverages(), http.StatusOK)
+	})
+}
+
+// ListOverages is the handler for the GET /v1/overages endpoint.
+//
+// swagger:route GET /v1/overages overages listOverages
+//
+// # List Overages
+//
+// Lists resource types in active subscriptions for which the usage is at or above the quota. An empty listing is
+// always returned if overage reporting is disabled.
+//
+// Responses:
+//
+//	200: subscriptionOverageListing
+//	400: badRequestResponse
+//	500: internalServerErrorResponse
+func (s Server) ListOverages(ctx echo.Context) error {
+	var err error
+
+	// Initialize the context for the endpoint.
+	var log = log.WithField("context", "list-overages")
+	var context = ctx.Request().Context()
+
+	// Extract the query parameters.
+	var offset int32 = 0
+	offset, err = query.ValidateIntQueryParam(ctx, "offset", &offset, "gte=0")
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	var limit int32 = 50
+	limit, err = query.ValidateIntQueryParam(ctx, "limit", &limit, "gte=0")
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	sortField := "username"
+	validSortFields := []string{"username", "resource-type", "overage", "overage-percentage"}
+	sortField, err = query.ValidateEnumQueryParam(ctx, "sort-field", validSortFields, &sortField)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	sortDir, err := query.ValidateSortDir(ctx)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	var minOveragePercentage float64 = 0
+	minOveragePercentage, err = query.ValidateFloatQueryParam(
+		ctx, "min-overage-percentage", &minOveragePercentage, "gte=0",
+	)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	search := ctx.QueryParam("search")
+	resourceTypeName := ctx.QueryParam("resource-type")
+
+	// Determine the sort field to pass to the database.
+	dbSortFieldFor := map[string]string{
+		"username":           "users.username",
+		"resource-type":      "resource_types.name",
+		"overage":            "usages.usage - quotas.quota",
+		"overage-percentage": "(usages.usage - quotas.quota) / NULLIF(quotas.quota, 0)",
+	}
+	dbSortField, ok := dbSortFieldFor[sortField]
+	if !ok {
+		err := fmt.Errorf("sort field name inconsistency detected for %s: please contact support", sortField)
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+
+	// Don't report any overages if overage reporting is disabled.
+	if !s.ReportOverages {
+		log.Debug("overage reporting is disabled")
+		return model.Success(
+			ctx,
+			&model.SubscriptionOverageListing{Overages: make([]*model.SubscriptionOverage, 0)},
+			http.StatusOK,
+		)
+	}
+
+	// Obtain the overage listing.
+	var overages []*model.SubscriptionOverage
+	var count int64
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		params := &db.OverageListingParams{
+			Offset:               int(offset),
+			Limit:                int(limit),
+			SortField:            dbSortField,
+			SortDir:              sortDir,
+			Search:               search,
+			ResourceTypeName:     resourceTypeName,
+			MinOveragePercentage: minOveragePercentage,
+		}
+		overages, count, err = db.ListOverages(context, tx, params)
+		return err
+	})
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+
+	// Build the result.
+	return model.Success(
+		ctx,
+		&model.SubscriptionOverageListing{
+			Overages: overages,
+			Total:    count,
+		},
+		http.StatusOK,
+	)
+}
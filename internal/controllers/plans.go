@@ -8,6 +8,7 @@ import (
 	"github.com/cyverse/qms/internal/db"
 	"github.com/cyverse/qms/internal/httpmodel"
 	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -28,7 +29,8 @@ func extractPlanID(ctx echo.Context) (string, error) {
 //
 // # List Plans
 //
-// Lists all the plans that are currently available.
+// Lists all the plans that are currently available. Archived plans are only included if the `include-archived` query
+// parameter is set to `true`.
 //
 // responses:
 //
@@ -40,7 +42,13 @@ func (s Server) GetAllPlans(ctx echo.Context) error {
 
 	context := ctx.Request().Context()
 
-	plans, err := db.ListPlans(context, s.GORMDB)
+	defaultIncludeArchived := false
+	includeArchived, err := query.ValidateBooleanQueryParam(ctx, "include-archived", &defaultIncludeArchived)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	plans, err := db.ListPlans(context, s.GORMDB, includeArchived)
 	if err != nil {
 		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
 	}
@@ -167,6 +175,165 @@ func (s Server) AddPlan(ctx echo.Context) error {
 	})
 }
 
+// UpdatePlan updates an existing plan.
+//
+// swagger:route PUT /v1/plans/{plan_id} plans updatePlan
+//
+// # Update Plan
+//
+// Updates the name and description of an existing plan. The quota defaults and rates are not modified by this endpoint.
+// The default plan may not be renamed.
+//
+// Responses:
+//
+//	200: planResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) UpdatePlan(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the plan ID.
+	planID, err := extractPlanID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "updating plan", "planID": planID})
+
+	// Parse and validate the request body.
+	var updatedPlan httpmodel.UpdatedPlan
+	if err = ctx.Bind(&updatedPlan); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	if err = updatedPlan.Validate(); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the plan exists.
+		plan, err := db.GetPlanByID(context, tx, planID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if plan == nil {
+			msg := fmt.Sprintf("plan ID %s not found", planID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// New users are subscribed to the default plan by name, so it can't be renamed.
+		if plan.Name == db.PlanNameBasic && updatedPlan.Name != plan.Name {
+			msg := fmt.Sprintf("the default plan, `%s`, may not be renamed", plan.Name)
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Verify that a different plan with the new name doesn't exist already.
+		homonym, err := db.GetPlan(context, tx, updatedPlan.Name)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if homonym != nil && *homonym.ID != *plan.ID {
+			msg := fmt.Sprintf("a plan named `%s` already exists", updatedPlan.Name)
+			return model.Error(ctx, msg, http.StatusConflict)
+		}
+
+		// Update the plan.
+		plan.Name = updatedPlan.Name
+		plan.Description = updatedPlan.Description
+		err = db.UpdatePlan(context, tx, plan)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		return model.Success(ctx, plan, http.StatusOK)
+	})
+}
+
+// setPlanArchived archives or restores a plan and returns the updated plan in the response.
+func (s Server) setPlanArchived(ctx echo.Context, archived bool) error {
+	var err error
+
+	// Extract and validate the plan ID.
+	planID, err := extractPlanID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "archiving plan", "planID": planID, "archived": archived})
+
+	// Begin a transaction.
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Verify that the plan exists.
+		plan, err := db.GetPlanByID(context, tx, planID)
+		if err != nil {
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		} else if plan == nil {
+			msg := fmt.Sprintf("plan ID %s not found", planID)
+			return model.Error(ctx, msg, http.StatusNotFound)
+		}
+
+		// New users are subscribed to the default plan automatically, so it can't be archived.
+		if archived && plan.Name == db.PlanNameBasic {
+			msg := fmt.Sprintf("the default plan, `%s`, may not be archived", plan.Name)
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Update the plan.
+		err = db.SetPlanArchived(context, tx, planID, archived)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		plan.Archived = archived
+
+		return model.Success(ctx, plan, http.StatusOK)
+	})
+}
+
+// ArchivePlan retires a plan so that no new subscriptions to it can be created.
+//
+// swagger:route POST /v1/plans/{plan_id}/archive plans archivePlan
+//
+// # Archive Plan
+//
+// Archives a plan. Archived plans are hidden from the plan listing by default, and new subscriptions to them can't be
+// created. Existing subscriptions to the plan are not affected. The default plan may not be archived.
+//
+// Responses:
+//
+//	200: planResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) ArchivePlan(ctx echo.Context) error {
+	return s.setPlanArchived(ctx, true)
+}
+
+// UnarchivePlan restores an archived plan so that new subscriptions to it can be created again.
+//
+// swagger:route POST /v1/plans/{plan_id}/unarchive plans unarchivePlan
+//
+// # Unarchive Plan
+//
+// Restores an archived plan, making it available for new subscriptions again.
+//
+// Responses:
+//
+//	200: planResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) UnarchivePlan(ctx echo.Context) error {
+	return s.setPlanArchived(ctx, false)
+}
+
 // GetActivePlanRate reports the active rate for an exisitng subscription plan.
 //
 // swagger:route GET /plans/{plan_id}/active-rate plans getPlanActiveRate
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/quotas"
+	"gorm.io/gorm"
+)
+
+// usagePool identifies the subscription that a user's resource usage is charged against. The member is only set if
+// the subscription is owned by an organization, in which case the pool is shared by all of the organization's members.
+type usagePool struct {
+	subscription *model.Subscription
+	member       *model.OrganizationMember
+}
+
+// rolloverIfDue begins any allotment periods of a subscription that have ended as of the given date, so that usage
+// recorded after the end of a period isn't charged against the completed period and then discarded when the period is
+// rolled over. Periods that haven't ended yet are never rolled over, even if the date is in the future. The
+// subscription details are reloaded if a rollover was needed. This function must be called before the reservation
+// lock for the subscription is acquired so that locks are always acquired in the same order.
+func rolloverIfDue(
+	ctx context.Context, tx *gorm.DB, subscription *model.Subscription, date time.Time,
+) (*model.Subscription, error) {
+	now := time.Now()
+	if date.Before(now) {
+		now = date
+	}
+	if !subscription.IsDueForRollover(now) {
+		return subscription, nil
+	}
+
+	// Lock the subscription so that the background job doesn't roll over the same period.
+	err := db.LockSubscriptionForRollover(ctx, tx, *subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = quotas.RolloverSubscription(ctx, tx, *subscription.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetSubscriptionDetails(ctx, tx, *subscription.ID)
+}
+
+// getOrganizationUsagePool returns the usage pool for the organization subscription that a user's resource usage is
+// charged against at the given time, with all of the subscription details loaded. A nil pointer is returned if the user
+// isn't a member of an organization, or if the organization has no subscription that is active at that time.
+func getOrganizationUsagePool(ctx context.Context, tx *gorm.DB, username string, date time.Time) (*usagePool, error) {
+	member, err := db.GetOrganizationMembership(ctx, tx, username)
+	if member == nil || err != nil {
+		return nil, err
+	}
+
+	// Look up the organization's subscription.
+	subscription, err := db.GetActiveOrganizationSubscriptionForDate(ctx, tx, *member.OrganizationID, date)
+	if subscription == nil || err != nil {
+		return nil, err
+	}
+	subscription, err = db.GetSubscriptionDetails(ctx, tx, *subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usagePool{subscription: subscription, member: member}, nil
+}
+
+// getUsagePool returns the usage pool that a user's resource usage is currently charged against, with all of the
+// subscription details loaded. Users who belong to an organization with an active subscription draw from the
+// organization's pool. Otherwise, the user's own active subscription is used, and a new subscription for the default
+// subscription plan is created if necessary.
+func getUsagePool(ctx context.Context, tx *gorm.DB, username string) (*usagePool, error) {
+	pool, err := getOrganizationUsagePool(ctx, tx, username, time.Now())
+	if pool != nil || err != nil {
+		return pool, err
+	}
+
+	// Fall back to the user's own subscription.
+	subscription, err := db.GetActiveSubscriptionDetails(ctx, tx, username)
+	if err != nil {
+		return nil, err
+	}
+	return &usagePool{subscription: subscription}, nil
+}
+
+// userID returns the identifier of the user whose resource usage is charged against the pool.
+func (p *usagePool) userID() *string {
+	if p.member != nil {
+		return p.member.UserID
+	}
+	return p.subscription.UserID
+}
+
+// checkQuota determines whether or not the given amount of a resource may be consumed from the pool. Amounts held by
+// outstanding reservations against the pool aren't available for consumption. Members of an organization are also
+// limited by their caps, if they have any.
+func (p *usagePool) checkQuota(
+	ctx context.Context, tx *gorm.DB, resourceType *model.ResourceType, amount float64,
+) (*model.QuotaCheckResult, error) {
+	subscriptionID := *p.subscription.ID
+
+	// Check the quota for the pool as a whole.
+	held, err := db.GetOutstandingReservationTotal(ctx, tx, subscriptionID, *resourceType.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := p.subscription.CheckQuota(resourceType, amount, held)
+	if p.member == nil {
+		return result, nil
+	}
+	result.Organization = p.member.Organization.Name
+
+	// Check the member's cap if there is one.
+	memberCap := p.member.GetCap(*resourceType.ID)
+	if memberCap == nil {
+		return result, nil
+	}
+	userID := *p.member.UserID
+	memberUsage, err := db.GetOrganizationMemberUsage(ctx, tx, subscriptionID, userID, *resourceType.ID)
+	if err != nil {
+		return nil, err
+	}
+	memberHeld, err := db.GetOutstandingMemberReservationTotal(ctx, tx, subscriptionID, userID, *resourceType.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.ApplyMemberCap(resourceType, *memberCap, memberUsage, memberHeld)
+
+	return result, nil
+}
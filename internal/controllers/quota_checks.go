@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/httpmodel"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// swagger:route POST /v1/users/{username}/check users checkQuota
+//
+// Check a Quota
+//
+// Determines whether or not the user may consume the requested amount of a resource based on the quota and usage
+// values in the user's active subscription, less any amounts held by outstanding reservations. Users may consume
+// resources up to the hard limit for the quota, which includes the grace allowance for the resource type. If the user
+// doesn't have an active subscription then a new subscription for the default subscription plan will be created.
+//
+// Users who belong to an organization with an active subscription consume resources from the organization's pool
+// instead of their own subscriptions. Members may also be limited by per-member caps, in which case the amount that the
+// member has consumed from the pool, less any amounts held by the member's outstanding reservations, is checked against
+// the cap as well.
+//
+// responses:
+//   200: quotaCheckResponse
+//   400: badRequestResponse
+//   500: internalServerErrorResponse
+
+// CheckQuota is the handler for the POST /v1/users/{username}/check endpoint.
+func (s Server) CheckQuota(c echo.Context) error {
+	log := log.WithField("context", "checking a quota")
+	ctx := c.Request().Context()
+
+	// Extract the username from the request.
+	username := strings.TrimSuffix(c.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		msg := fmt.Sprintf("invalid username provided in request: '%s'", c.Param("username"))
+		log.Error(msg)
+		return model.Error(c, msg, http.StatusBadRequest)
+	}
+	log = log.WithField("user", username)
+
+	// Parse the request body.
+	var body httpmodel.QuotaCheckRequest
+	err := c.Bind(&body)
+	if err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		log.Error(msg)
+		return model.Error(c, msg, http.StatusBadRequest)
+	}
+	if err = c.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		log.Error(msg)
+		return model.Error(c, msg, http.StatusBadRequest)
+	}
+	log = log.WithField("resource-type", body.ResourceTypeName)
+
+	// Start a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		// Look up the resource type.
+		resourceType, err := db.GetResourceTypeByName(ctx, tx, body.ResourceTypeName)
+		if err != nil {
+			log.Error(err)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
+		}
+		if resourceType == nil {
+			msg := fmt.Sprintf("resource type '%s' not found", body.ResourceTypeName)
+			log.Error(msg)
+			return rollback(c, msg, http.StatusBadRequest)
+		}
+
+		// Load the user's usage pool, creating a new subscription if necessary.
+		pool, err := getUsagePool(ctx, tx, username)
+		if err != nil {
+			log.Error(err)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Begin a new allotment period first if the current one has ended.
+		pool.subscription, err = rolloverIfDue(ctx, tx, pool.subscription, time.Now())
+		if err != nil {
+			log.Error(err)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Make the decision.
+		result, err := pool.checkQuota(ctx, tx, resourceType, body.Amount)
+		if err != nil {
+			log.Error(err)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debugf("quota check result: %+v", result)
+
+		return model.Success(c, result, http.StatusOK)
+	})
+	return transactionResult(err)
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/httpmodel"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/quotas"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// grantQuota applies a bulk quota grant to a single user's active subscription, creating a new subscription for the
+// default subscription plan if necessary. The user's new quota value is returned.
+func grantQuota(
+	ctx context.Context,
+	tx *gorm.DB,
+	username string,
+	resourceType *model.ResourceType,
+	updateOperation *model.UpdateOperation,
+	amount float64,
+	opts *quotas.UpdateOptions,
+) (float64, error) {
+	// Load the user's current subscription, creating a new subscription if necessary.
+	subscription, err := db.GetActiveSubscriptionDetails(ctx, tx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	// Active quota overrides remain in effect on top of a quota that is set directly.
+	if updateOperation.Name == model.UpdateOperationSet {
+		overrideTotal, err := db.GetActiveQuotaOverrideTotal(ctx, tx, *subscription.ID, *resourceType.ID)
+		if err != nil {
+			return 0, err
+		}
+		amount += overrideTotal
+	}
+
+	return quotas.Update(ctx, tx, subscription, resourceType.ID, updateOperation, amount, opts)
+}
+
+// GrantQuotas changes the same quota for several users at once.
+//
+// swagger:route POST /v1/quota-grants quotas grantQuotas
+//
+// # Grant Quotas to Multiple Users
+//
+// Changes the quota for a resource type in each listed user's active subscription, either by adding the requested
+// amount to the quota or by setting the quota to the requested amount. If a user doesn't have an active subscription
+// then a new subscription for the default subscription plan will be created. Each user is processed in its own
+// transaction, so that a failure for one user doesn't prevent the others from receiving the grant. Every quota change
+// is recorded in the updates table with the batch ID returned in the response, along with the value of the `actor`
+// query parameter if it's specified. As with individual quota changes, setting a quota only changes the base quota;
+// active quota overrides remain in effect on top of it.
+//
+// Responses:
+//
+//	200: quotaGrantResponse
+//	400: badRequestResponse
+//	500: internalServerErrorResponse
+func (s Server) GrantQuotas(ctx echo.Context) error {
+	var err error
+
+	// Initialize the context for the endpoint.
+	log := log.WithFields(logrus.Fields{"context": "granting quotas in bulk"})
+	context := ctx.Request().Context()
+
+	// Parse and validate the request body.
+	var body httpmodel.QuotaGrant
+	if err = ctx.Bind(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if err = ctx.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	log = log.WithFields(logrus.Fields{"resource-type": body.ResourceTypeName, "mode": body.Mode})
+
+	// Look up the resource type.
+	resourceType, err := db.GetResourceTypeByName(context, s.GORMDB, body.ResourceTypeName)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+	if resourceType == nil {
+		msg := fmt.Sprintf("resource type '%s' not found", body.ResourceTypeName)
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+
+	// Look up the update operation used to record the quota changes.
+	updateType := UpdateTypeAdd
+	if body.Mode == httpmodel.QuotaGrantModeSet {
+		updateType = UpdateTypeSet
+	}
+	updateOperation, err := db.GetUpdateOperation(context, s.GORMDB, updateType)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	} else if updateOperation == nil {
+		msg := fmt.Sprintf("update operation %s not found", updateType)
+		return model.Error(ctx, msg, http.StatusInternalServerError)
+	}
+
+	// Generate the batch ID shared by all of the quota changes.
+	batchID, err := db.NewBatchID(context, s.GORMDB)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+	log = log.WithField("batch-id", batchID)
+	opts := &quotas.UpdateOptions{Actor: extractActor(ctx), BatchID: &batchID}
+
+	// Apply the grant to each user in a separate transaction. Users listed more than once only receive the grant once.
+	report := &httpmodel.QuotaGrantReport{BatchID: batchID, Results: make([]*httpmodel.QuotaGrantResult, 0)}
+	seen := make(map[string]bool)
+	for _, requestedUsername := range body.Usernames {
+		username := strings.TrimSuffix(requestedUsername, s.UsernameSuffix)
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		result := &httpmodel.QuotaGrantResult{Username: username}
+		report.Results = append(report.Results, result)
+		if username == "" {
+			msg := fmt.Sprintf("invalid username: '%s'", requestedUsername)
+			result.FailureReason = &msg
+			continue
+		}
+
+		err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+			quota, err := grantQuota(context, tx, username, resourceType, updateOperation, body.Amount, opts)
+			if err != nil {
+				return err
+			}
+			result.Quota = &quota
+			return nil
+		})
+		if err != nil {
+			log.Errorf("unable to grant the quota to %s: %s", username, err.Error())
+			msg := err.Error()
+			result.Quota = nil
+			result.FailureReason = &msg
+			continue
+		}
+		result.Success = true
+	}
+
+	return model.Success(ctx, report, http.StatusOK)
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cyverse-de/echo-middleware/v2/params"
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/httpmodel"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
+	"github.com/cyverse/qms/internal/quotas"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// extractQuotaOverrideID extracts and validates the quota override ID path parameter.
+func extractQuotaOverrideID(ctx echo.Context) (string, error) {
+	overrideID, err := params.ValidatedPathParam(ctx, "override_id", "uuid_rfc4122")
+	if err != nil {
+		return "", fmt.Errorf("the quota override ID must be a valid UUID")
+	}
+	return overrideID, nil
+}
+
+// AddQuotaOverride temporarily increases a quota in a user's subscription.
+//
+// swagger:route POST /v1/users/{username}/quota-overrides users addQuotaOverride
+//
+// # Add a Quota Override
+//
+// Temporarily increases a quota in the user's subscription by the requested amount. The override applies to the
+// subscription that is active at its effective start date. If the start date has already passed then the quota is
+// raised immediately; otherwise the quota is raised automatically once the start date passes. The quota is lowered
+// again automatically when the override expires. Each change to the quota is recorded in the updates table, along with
+// the value of the `actor` query parameter if it's specified. If the user doesn't have an active subscription then a
+// new subscription for the default subscription plan will be created.
+//
+// Responses:
+//
+//	200: quotaOverrideResponse
+//	400: badRequestResponse
+//	500: internalServerErrorResponse
+func (s Server) AddQuotaOverride(ctx echo.Context) error {
+	var err error
+
+	// Extract the username from the request.
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		msg := fmt.Sprintf("invalid username provided in request: '%s'", ctx.Param("username"))
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "adding a quota override", "user": username})
+
+	// Parse and validate the request body.
+	var body httpmodel.NewQuotaOverride
+	if err = ctx.Bind(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if err = ctx.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	now := time.Now()
+	startDate := body.GetEffectiveStartDate(now)
+	endDate := body.GetEffectiveEndDate()
+	if !endDate.After(startDate) {
+		return model.Error(ctx, "the effective end date must be after the effective start date", http.StatusBadRequest)
+	}
+	if !endDate.After(now) {
+		return model.Error(ctx, "the effective end date must be in the future", http.StatusBadRequest)
+	}
+	actor := extractActor(ctx)
+	log = log.WithField("resource-type", body.ResourceTypeName)
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up the resource type.
+		resourceType, err := db.GetResourceTypeByName(context, tx, body.ResourceTypeName)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if resourceType == nil {
+			msg := fmt.Sprintf("resource type '%s' not found", body.ResourceTypeName)
+			return rollback(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Look up the user's active subscription, creating a new subscription if necessary.
+		subscription, err := db.GetActiveSubscription(context, tx, username)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Overrides that start in the future apply to the subscription that will be active at that time.
+		if startDate.After(now) {
+			subscription, err = db.GetActiveSubscriptionForDate(context, tx, username, startDate)
+			if err != nil {
+				log.Error(err)
+				return rollback(ctx, err.Error(), http.StatusInternalServerError)
+			}
+			if subscription == nil {
+				msg := "the user has no subscription that will be active at the effective start date"
+				return rollback(ctx, msg, http.StatusBadRequest)
+			}
+		}
+
+		// Record the quota override.
+		override := model.QuotaOverride{
+			SubscriptionID:     subscription.ID,
+			ResourceTypeID:     resourceType.ID,
+			Amount:             body.Amount,
+			Reason:             body.Reason,
+			Status:             model.QuotaOverrideStatusPending,
+			EffectiveStartDate: startDate,
+			EffectiveEndDate:   endDate,
+			Actor:              actor,
+		}
+		err = db.SaveQuotaOverride(context, tx, &override)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Raise the quota immediately if the override is already in effect.
+		if override.IsDueForActivation(now) {
+			err = quotas.ActivateOverride(context, tx, &override, actor)
+			if err != nil {
+				log.Error(err)
+				return rollback(ctx, err.Error(), http.StatusInternalServerError)
+			}
+		}
+		log.Debugf("added a quota override of %f %s", body.Amount, resourceType.Unit)
+
+		// Look up the quota override with all of its details and return it in the response.
+		saved, err := db.GetQuotaOverride(context, tx, *override.ID, false)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		return model.Success(ctx, saved, http.StatusOK)
+	})
+	return transactionResult(err)
+}
+
+// ListQuotaOverrides lists the quota overrides in a user's subscriptions.
+//
+// swagger:route GET /v1/users/{username}/quota-overrides users listQuotaOverrides
+//
+// # List Quota Overrides
+//
+// Lists the quota overrides in all of the user's subscriptions, most recent first. Overrides that have been retired are
+// only included if the `include-retired` query parameter is set to `true`.
+//
+// Responses:
+//
+//	200: quotaOverrideListingResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) ListQuotaOverrides(ctx echo.Context) error {
+	var err error
+
+	log := log.WithFields(logrus.Fields{"context": "listing quota overrides"})
+	context := ctx.Request().Context()
+
+	// Extract the username.
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		return model.Error(ctx, "invalid username", http.StatusBadRequest)
+	}
+	log = log.WithFields(logrus.Fields{"user": username})
+
+	// Extract the query parameters.
+	defaultIncludeRetired := false
+	includeRetired, err := query.ValidateBooleanQueryParam(ctx, "include-retired", &defaultIncludeRetired)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Verify that the user exists.
+	err = s.ValidateUser(ctx, username)
+	if err != nil {
+		return nil
+	}
+
+	// List the quota overrides.
+	overrides, err := db.ListQuotaOverridesForUser(context, s.GORMDB, username, includeRetired)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+
+	return model.Success(ctx, overrides, http.StatusOK)
+}
+
+// RetireQuotaOverride retires a quota override before it expires.
+//
+// swagger:route DELETE /v1/quota-overrides/{override_id} quota-overrides retireQuotaOverride
+//
+// # Retire a Quota Override
+//
+// Retires a quota override before its effective end date. If the override is active then the quota is lowered by the
+// override amount, and the change is recorded in the updates table along with the value of the `actor` query parameter
+// if it's specified. Overrides that have already been retired may not be retired again.
+//
+// Responses:
+//
+//	200: quotaOverrideResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) RetireQuotaOverride(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the quota override ID.
+	overrideID, err := extractQuotaOverrideID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "retiring a quota override", "override_id": overrideID})
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up and lock the quota override.
+		override, err := db.GetQuotaOverride(context, tx, overrideID, true)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if override == nil {
+			msg := fmt.Sprintf("quota override ID %s not found", overrideID)
+			return rollback(ctx, msg, http.StatusNotFound)
+		}
+		if override.Status == model.QuotaOverrideStatusRetired {
+			msg := fmt.Sprintf("quota override ID %s has already been retired", overrideID)
+			return rollback(ctx, msg, http.StatusConflict)
+		}
+
+		// Retire the quota override.
+		err = quotas.RetireOverride(context, tx, override, extractActor(ctx), time.Now())
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		return model.Success(ctx, override, http.StatusOK)
+	})
+	return transactionResult(err)
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// extractActor returns the value of the actor query parameter, or nil if the parameter wasn't specified.
+func extractActor(ctx echo.Context) *string {
+	actor := strings.TrimSpace(ctx.QueryParam("actor"))
+	if actor == "" {
+		return nil
+	}
+	return &actor
+}
+
+// GetQuotaHistory lists the changes made to a user's quotas.
+//
+// swagger:route GET /v1/users/{username}/quota-history users getQuotaHistory
+//
+// # Get Quota History
+//
+// Lists the changes made to the quotas in all of a user's subscriptions, including the operation, the previous and new
+// quota values, and the person or service that requested the change if it's known.
+//
+// Responses:
+//
+//	200: updateListingResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) GetQuotaHistory(ctx echo.Context) error {
+	var err error
+
+	log := log.WithFields(logrus.Fields{"context": "getting quota history"})
+	context := ctx.Request().Context()
+
+	// Extract the username.
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		return model.Error(ctx, "invalid username", http.StatusBadRequest)
+	}
+	log = log.WithFields(logrus.Fields{"user": username})
+
+	// Extract the query parameters.
+	var offset int32 = 0
+	offset, err = query.ValidateIntQueryParam(ctx, "offset", &offset, "gte=0")
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	var limit int32 = 50
+	limit, err = query.ValidateIntQueryParam(ctx, "limit", &limit, "gte=0")
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	sortDir, err := query.ValidateSortDir(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Verify that the user exists.
+	err = s.ValidateUser(ctx, username)
+	if err != nil {
+		return nil
+	}
+
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		params := &db.UpdateListingParams{
+			Offset:    int(offset),
+			Limit:     int(limit),
+			SortDir:   sortDir,
+			Username:  username,
+			ValueType: model.ValueTypeQuotas,
+		}
+
+		// Look up the resource type if one was specified.
+		resourceTypeName := ctx.QueryParam("resource-type")
+		if resourceTypeName != "" {
+			resourceType, err := db.GetResourceTypeByName(context, tx, resourceTypeName)
+			if err != nil {
+				log.Error(err)
+				return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			}
+			if resourceType == nil {
+				msg := fmt.Sprintf("resource type '%s' not found", resourceTypeName)
+				return model.Error(ctx, msg, http.StatusBadRequest)
+			}
+			params.ResourceTypeID = resourceType.ID
+		}
+
+		// Obtain the quota history.
+		updates, count, err := db.ListUpdates(context, tx, params)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		return model.Success(ctx, &model.UpdateListing{Updates: updates, Total: count}, http.StatusOK)
+	})
+}
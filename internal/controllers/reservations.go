@@ -0,0 +1,404 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cyverse-de/echo-middleware/v2/params"
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/httpmodel"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// extractReservationID extracts and validates the reservation ID path parameter.
+func extractReservationID(ctx echo.Context) (string, error) {
+	reservationID, err := params.ValidatedPathParam(ctx, "reservation_id", "uuid_rfc4122")
+	if err != nil {
+		return "", fmt.Errorf("the reservation ID must be a valid UUID")
+	}
+	return reservationID, nil
+}
+
+// reservationMetadata returns the metadata to record with usage updates made when a reservation is committed.
+func reservationMetadata(reservation *model.Reservation) model.Metadata {
+	return model.Metadata{"reservation_id": *reservation.ID}
+}
+
+// AddReservation places a hold on part of a user's remaining allowance for a resource type.
+//
+// swagger:route POST /v1/users/{username}/reservations reservations addReservation
+//
+// # Place a Hold on a Resource
+//
+// Places a hold on part of the remaining allowance for a resource type in the user's active subscription. The
+// remaining allowance is the hard limit for the quota minus the current usage minus the amounts held by other
+// outstanding reservations. Users who belong to an organization with an active subscription place holds against the
+// organization's pool, subject to any per-member caps.
+// The hold expires automatically at the requested expiration time, or after the configured reservation lifetime if no
+// expiration time is requested. If the user doesn't have an active subscription then a new subscription for the
+// default subscription plan will be created.
+//
+// Responses:
+//
+//	200: reservationResponse
+//	400: badRequestResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) AddReservation(ctx echo.Context) error {
+	var err error
+
+	// Extract the username from the request.
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		msg := fmt.Sprintf("invalid username provided in request: '%s'", ctx.Param("username"))
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "adding a reservation", "user": username})
+
+	// Parse and validate the request body.
+	var body httpmodel.NewReservation
+	if err = ctx.Bind(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if err = ctx.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	now := time.Now()
+	expiresAt := body.GetExpiresAt(now, s.ReservationLifetime)
+	if !expiresAt.After(now) {
+		return model.Error(ctx, "the expiration time must be in the future", http.StatusBadRequest)
+	}
+	log = log.WithField("resource-type", body.ResourceTypeName)
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up the resource type.
+		resourceType, err := db.GetResourceTypeByName(context, tx, body.ResourceTypeName)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if resourceType == nil {
+			msg := fmt.Sprintf("resource type '%s' not found", body.ResourceTypeName)
+			return rollback(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Look up the user's usage pool, creating a new subscription if necessary.
+		pool, err := getUsagePool(context, tx, username)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		subscriptionID := *pool.subscription.ID
+
+		// Begin a new allotment period first if the current one has ended.
+		_, err = rolloverIfDue(context, tx, pool.subscription, time.Now())
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Prevent concurrent requests from placing holds against the same allowance.
+		err = db.LockReservations(context, tx, subscriptionID, *resourceType.ID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Load the subscription details now that we have the lock, so that the quota and usage are current.
+		pool.subscription, err = db.GetSubscriptionDetails(context, tx, subscriptionID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Determine whether or not the hold may be placed.
+		result, err := pool.checkQuota(context, tx, resourceType, body.Amount)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if !result.Allowed {
+			return rollback(ctx, result.Reason, http.StatusConflict)
+		}
+
+		// Record the reservation.
+		reservation := model.Reservation{
+			SubscriptionID: &subscriptionID,
+			UserID:         pool.userID(),
+			ResourceTypeID: resourceType.ID,
+			Amount:         body.Amount,
+			Status:         model.ReservationStatusHeld,
+			ExpiresAt:      expiresAt,
+		}
+		err = db.SaveReservation(context, tx, &reservation)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debugf("placed a hold of %f %s", body.Amount, resourceType.Unit)
+
+		// Look up the reservation with all of its details and return it in the response.
+		saved, err := db.GetReservation(context, tx, *reservation.ID, false)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		return model.Success(ctx, saved, http.StatusOK)
+	})
+	return transactionResult(err)
+}
+
+// GetReservation returns information about a reservation.
+//
+// swagger:route GET /v1/reservations/{reservation_id} reservations getReservation
+//
+// # Get Reservation Information
+//
+// Returns information about the reservation with the given identifier.
+//
+// Responses:
+//
+//	200: reservationResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) GetReservation(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the reservation ID.
+	reservationID, err := extractReservationID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "getting a reservation", "reservation_id": reservationID})
+
+	// Look up the reservation.
+	reservation, err := db.GetReservation(ctx.Request().Context(), s.GORMDB, reservationID, false)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+	if reservation == nil {
+		msg := fmt.Sprintf("reservation ID %s not found", reservationID)
+		return model.Error(ctx, msg, http.StatusNotFound)
+	}
+
+	return model.Success(ctx, reservation, http.StatusOK)
+}
+
+// CommitReservation records the amount of a resource that was actually consumed for a reservation.
+//
+// swagger:route POST /v1/reservations/{reservation_id}/commit reservations commitReservation
+//
+// # Commit a Reservation
+//
+// Records the amount of a resource that was actually consumed for a reservation as an ADD usage update against the
+// subscription that the hold was placed against, and marks the reservation as committed. The committed amount may
+// differ from the reserved amount. Holds that have expired may still be committed, but holds that have already been
+// committed or released may not.
+//
+// Responses:
+//
+//	200: reservationResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) CommitReservation(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the reservation ID.
+	reservationID, err := extractReservationID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "committing a reservation", "reservation_id": reservationID})
+
+	// Parse and validate the request body.
+	var body httpmodel.ReservationCommit
+	if err = ctx.Bind(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if err = ctx.Validate(&body); err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err.Error())
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up and lock the reservation.
+		reservation, err := db.GetReservation(context, tx, reservationID, true)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if reservation == nil {
+			msg := fmt.Sprintf("reservation ID %s not found", reservationID)
+			return rollback(ctx, msg, http.StatusNotFound)
+		}
+		if !reservation.IsOpen() {
+			msg := fmt.Sprintf("reservation ID %s has already been %s", reservationID, reservation.Status)
+			return rollback(ctx, msg, http.StatusConflict)
+		}
+
+		// Begin a new allotment period first if the current period of the subscription that the hold was placed
+		// against has ended.
+		subscription, err := db.GetSubscriptionDetails(context, tx, *reservation.SubscriptionID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		_, err = rolloverIfDue(context, tx, subscription, time.Now())
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Prevent concurrent holds, commits, and usage updates from changing the same allowance.
+		err = db.LockReservations(context, tx, *reservation.SubscriptionID, *reservation.ResourceTypeID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Reload the subscription now that we have the lock.
+		subscription, err = db.GetSubscriptionDetails(context, tx, *reservation.SubscriptionID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Look up the update operation used to record the usage.
+		updateOperation, err := db.GetUpdateOperation(context, tx, UpdateTypeAdd)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		} else if updateOperation == nil {
+			msg := fmt.Sprintf("update operation %s not found", UpdateTypeAdd)
+			return rollback(ctx, msg, http.StatusInternalServerError)
+		}
+
+		// Holds against an organization's pool are charged to the member who placed them, as long as that user still
+		// belongs to the organization. Otherwise, the usage is only charged to the pool, but the update still records
+		// the user who placed the hold.
+		opts := &usageUpdateOptions{Metadata: reservationMetadata(reservation)}
+		if subscription.OrganizationID != nil && reservation.UserID != nil {
+			member, err := db.GetOrganizationMembershipForUserID(context, tx, *reservation.UserID)
+			if err != nil {
+				log.Error(err)
+				return rollback(ctx, err.Error(), http.StatusInternalServerError)
+			}
+			if member != nil && *member.OrganizationID == *subscription.OrganizationID {
+				opts.Member = member
+			} else {
+				opts.User, err = db.GetUserByID(context, tx, *reservation.UserID)
+				if err != nil {
+					log.Error(err)
+					return rollback(ctx, err.Error(), http.StatusInternalServerError)
+				}
+			}
+		}
+
+		// Record the usage.
+		err = recordUsageUpdate(
+			context, tx, subscription, reservation.ResourceType, updateOperation, body.Amount, opts,
+		)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Mark the reservation as committed.
+		reservation.Status = model.ReservationStatusCommitted
+		reservation.CommittedAmount = &body.Amount
+		err = db.UpdateReservationStatus(context, tx, reservation)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debugf("committed %f %s", body.Amount, reservation.ResourceType.Unit)
+
+		return model.Success(ctx, reservation, http.StatusOK)
+	})
+	return transactionResult(err)
+}
+
+// ReleaseReservation releases the hold placed by a reservation without recording any usage.
+//
+// swagger:route DELETE /v1/reservations/{reservation_id} reservations releaseReservation
+//
+// # Release a Reservation
+//
+// Releases the hold placed by a reservation without recording any usage, making the reserved amount available to
+// other callers again. Holds that have already been committed or released may not be released.
+//
+// Responses:
+//
+//	200: reservationResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) ReleaseReservation(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the reservation ID.
+	reservationID, err := extractReservationID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger.
+	log := log.WithFields(logrus.Fields{"context": "releasing a reservation", "reservation_id": reservationID})
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up and lock the reservation.
+		reservation, err := db.GetReservation(context, tx, reservationID, true)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if reservation == nil {
+			msg := fmt.Sprintf("reservation ID %s not found", reservationID)
+			return rollback(ctx, msg, http.StatusNotFound)
+		}
+		if !reservation.IsOpen() {
+			msg := fmt.Sprintf("reservation ID %s has already been %s", reservationID, reservation.Status)
+			return rollback(ctx, msg, http.StatusConflict)
+		}
+
+		// Mark the reservation as released.
+		reservation.Status = model.ReservationStatusReleased
+		err = db.UpdateReservationStatus(context, tx, reservation)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debug("released the reservation")
+
+		return model.Success(ctx, reservation, http.StatusOK)
+	})
+	return transactionResult(err)
+}
@@ -53,7 +53,8 @@ func (s Server) ListResourceTypes(ctx echo.Context) error {
 //
 // Add Resource Type
 //
-// Adds a new resource type to the qms database.
+// Adds a new resource type to the qms database. The warning percentage defaults to 90 and the grace percentage
+// defaults to 0 if they're not specified.
 //
 // responses:
 //   200: resourceTypeDetails
@@ -69,7 +70,7 @@ func (s Server) AddResourceType(ctx echo.Context) error {
 	log := log.WithFields(logrus.Fields{"context": "adding resource type"})
 
 	//  Extract and validate the request body.
-	var resourceType model.ResourceType
+	resourceType := model.NewResourceType()
 	if err = ctx.Bind(&resourceType); err != nil {
 		msg := fmt.Sprintf("invalid request body: %s", err)
 		return model.Error(ctx, msg, http.StatusBadRequest)
@@ -78,6 +79,9 @@ func (s Server) AddResourceType(ctx echo.Context) error {
 		msg := "the resource type name and unit are both required"
 		return model.Error(ctx, msg, http.StatusBadRequest)
 	}
+	if err = resourceType.ValidateLimits(); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
 
 	log.Debugf("adding resource type %s with unit %s", resourceType.Name, resourceType.Unit)
 
@@ -142,7 +146,8 @@ func (s Server) GetResourceTypeDetails(ctx echo.Context) error {
 //
 // Update Resource Type
 //
-// Updates an existing resource type in the qms database.
+// Updates an existing resource type in the qms database. The warning percentage defaults to 90 and the grace
+// percentage defaults to 0 if they're not specified.
 //
 // responses:
 //   200: resourceTypeDetails
@@ -166,7 +171,7 @@ func (s Server) UpdateResourceType(ctx echo.Context) error {
 	log = log.WithFields(logrus.Fields{"resourceTypeID": resourceTypeID})
 
 	//  Extract and validate the request body.
-	var inboundResourceType model.ResourceType
+	inboundResourceType := model.NewResourceType()
 	if err = ctx.Bind(&inboundResourceType); err != nil {
 		msg := fmt.Sprintf("invalid request body: %s", err)
 		return model.Error(ctx, msg, http.StatusBadRequest)
@@ -175,6 +180,9 @@ func (s Server) UpdateResourceType(ctx echo.Context) error {
 		msg := "the resource type name and unit are both required"
 		return model.Error(ctx, msg, http.StatusBadRequest)
 	}
+	if err = inboundResourceType.ValidateLimits(); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
 
 	log.Debug("extracted and validated the request body")
 
@@ -208,6 +216,8 @@ func (s Server) UpdateResourceType(ctx echo.Context) error {
 		existingResourceType.Name = inboundResourceType.Name
 		existingResourceType.Unit = inboundResourceType.Unit
 		existingResourceType.Consumable = inboundResourceType.Consumable
+		existingResourceType.WarningPercentage = inboundResourceType.WarningPercentage
+		existingResourceType.GracePercentage = inboundResourceType.GracePercentage
 		err = db.UpdateResourceType(context, tx, *existingResourceType)
 		if err != nil {
 			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
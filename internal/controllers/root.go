@@ -3,6 +3,7 @@ package controllers
 import (
 	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/cyverse/qms/internal/model"
 	"github.com/cyverse/qms/logging"
@@ -15,14 +16,16 @@ var log = logging.GetLogger().WithFields(logrus.Fields{"package": "controllers"}
 
 // Server defines the REST API of the qms
 type Server struct {
-	Router         *echo.Echo
-	DB             *sql.DB
-	GORMDB         *gorm.DB
-	Service        string
-	Title          string
-	Version        string
-	ReportOverages bool
-	UsernameSuffix string
+	Router              *echo.Echo
+	DB                  *sql.DB
+	GORMDB              *gorm.DB
+	Service             string
+	Title               string
+	Version             string
+	ReportOverages      bool
+	UsernameSuffix      string
+	ReservationLifetime time.Duration
+	UsageBatchLimit     int
 }
 
 // ServerInfo returns basic information about the server.
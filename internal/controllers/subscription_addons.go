@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cyverse-de/echo-middleware/v2/params"
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/httpmodel"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
+	"github.com/cyverse/qms/internal/quotas"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// extractSubscriptionID extracts and validates the subscription ID path parameter.
+func extractSubscriptionID(ctx echo.Context) (string, error) {
+	subscriptionID, err := params.ValidatedPathParam(ctx, "subscription_id", "uuid_rfc4122")
+	if err != nil {
+		return "", fmt.Errorf("the subscription ID must be a valid UUID")
+	}
+	return subscriptionID, nil
+}
+
+// extractSubscriptionAddonID extracts and validates the subscription addon ID path parameter.
+func extractSubscriptionAddonID(ctx echo.Context) (string, error) {
+	subscriptionAddonID, err := params.ValidatedPathParam(ctx, "subscription_addon_id", "uuid_rfc4122")
+	if err != nil {
+		return "", fmt.Errorf("the subscription addon ID must be a valid UUID")
+	}
+	return subscriptionAddonID, nil
+}
+
+// subscriptionAddonMetadata returns the metadata to record with updates made because of a subscription addon.
+func subscriptionAddonMetadata(subscriptionAddon *model.SubscriptionAddon) model.Metadata {
+	return model.Metadata{"subscription_addon_id": *subscriptionAddon.ID}
+}
+
+// AddSubscriptionAddon applies an addon to an existing subscription.
+//
+// swagger:route POST /v1/subscriptions/{subscription_id}/addons subscriptions addSubscriptionAddon
+//
+// # Apply an Addon to a Subscription
+//
+// Applies an addon to an existing subscription at the addon's currently active rate. The quota for the resource type
+// associated with the addon is increased by the addon amount, and the quota change is recorded in the updates table.
+//
+// Responses:
+//
+//	200: subscriptionAddonResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) AddSubscriptionAddon(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the subscription ID.
+	subscriptionID, err := extractSubscriptionID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger and log a message indicating what is being done.
+	log := log.WithFields(
+		logrus.Fields{
+			"context":         "adding subscription addon",
+			"subscription_id": subscriptionID,
+		},
+	)
+	log.Info("applying an addon to an existing subscription")
+
+	// Parse and validate the request body.
+	var body httpmodel.NewSubscriptionAddon
+	if err = ctx.Bind(&body); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	if err = body.Validate(); err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	log = log.WithField("addon_id", body.AddonID)
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up the subscription.
+		subscription, err := db.GetSubscriptionDetails(context, tx, subscriptionID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			msg := fmt.Sprintf("subscription ID %s not found", subscriptionID)
+			return rollback(ctx, msg, http.StatusNotFound)
+		} else if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Look up the addon.
+		addon, err := db.GetAddonByID(context, tx, body.AddonID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		} else if addon == nil {
+			msg := fmt.Sprintf("addon ID %s not found", body.AddonID)
+			return rollback(ctx, msg, http.StatusBadRequest)
+		}
+
+		// Look up the active rate for the addon.
+		addonRate, err := addon.GetActiveAddonRate()
+		if err != nil {
+			return rollback(ctx, err.Error(), http.StatusBadRequest)
+		}
+
+		// Look up the update operation used to record the quota change.
+		updateOperation, err := db.GetUpdateOperation(context, tx, UpdateTypeAdd)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		} else if updateOperation == nil {
+			msg := fmt.Sprintf("update operation %s not found", UpdateTypeAdd)
+			return rollback(ctx, msg, http.StatusInternalServerError)
+		}
+
+		// Record the subscription addon.
+		subscriptionAddon := body.ToDBModel(subscriptionID, addon, addonRate)
+		err = db.SaveSubscriptionAddon(context, tx, &subscriptionAddon)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debug("recorded the subscription addon")
+
+		// Raise the quota for the resource type associated with the addon and record the change.
+		opts := &quotas.UpdateOptions{Metadata: subscriptionAddonMetadata(&subscriptionAddon), Actor: extractActor(ctx)}
+		newQuotaValue, err := quotas.Update(
+			context, tx, subscription, addon.ResourceTypeID, updateOperation, subscriptionAddon.Amount, opts,
+		)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debugf("raised the %s quota to %f", addon.ResourceType.Name, newQuotaValue)
+
+		// Look up the subscription addon with all of its details and return it in the response.
+		result, err := db.GetSubscriptionAddon(context, tx, subscriptionID, *subscriptionAddon.ID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		} else if result == nil {
+			msg := fmt.Sprintf("subscription addon ID %s not found after saving it", *subscriptionAddon.ID)
+			return rollback(ctx, msg, http.StatusInternalServerError)
+		}
+		return model.Success(ctx, result, http.StatusOK)
+	})
+	return transactionResult(err)
+}
+
+// DeleteSubscriptionAddon removes an addon from an existing subscription.
+//
+// swagger:route DELETE /v1/subscriptions/{subscription_id}/addons/{subscription_addon_id} subscriptions deleteSubscriptionAddon
+//
+// # Remove an Addon from a Subscription
+//
+// Removes an addon from an existing subscription. The quota for the resource type associated with the addon is reduced
+// by the addon amount, and the quota change is recorded in the updates table. The response includes the amount that
+// should be refunded to the user, which is based on the addon rate that was in effect when the addon was applied. The
+// quota for a non-consumable resource type will not be reduced below the current usage unless the `force` query
+// parameter is set to `true`.
+//
+// Responses:
+//
+//	200: subscriptionAddonRemovalResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	409: conflictResponse
+//	500: internalServerErrorResponse
+func (s Server) DeleteSubscriptionAddon(ctx echo.Context) error {
+	var err error
+
+	// Extract and validate the subscription ID.
+	subscriptionID, err := extractSubscriptionID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Extract and validate the subscription addon ID.
+	subscriptionAddonID, err := extractSubscriptionAddonID(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Get the value of the `force` query parameter.
+	force := false
+	force, err = query.ValidateBooleanQueryParam(ctx, "force", &force)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Initialize the logger and log a message indicating what is being done.
+	log := log.WithFields(
+		logrus.Fields{
+			"context":               "deleting subscription addon",
+			"subscription_id":       subscriptionID,
+			"subscription_addon_id": subscriptionAddonID,
+			"force":                 force,
+		},
+	)
+	log.Info("removing an addon from an existing subscription")
+
+	// Begin a transaction.
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		context := ctx.Request().Context()
+
+		// Look up the subscription.
+		subscription, err := db.GetSubscriptionDetails(context, tx, subscriptionID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			msg := fmt.Sprintf("subscription ID %s not found", subscriptionID)
+			return rollback(ctx, msg, http.StatusNotFound)
+		} else if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Look up the subscription addon.
+		subscriptionAddon, err := db.GetSubscriptionAddon(context, tx, subscriptionID, subscriptionAddonID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		} else if subscriptionAddon == nil {
+			msg := fmt.Sprintf("subscription addon ID %s not found", subscriptionAddonID)
+			return rollback(ctx, msg, http.StatusNotFound)
+		}
+		resourceType := subscriptionAddon.Addon.ResourceType
+
+		// Determine the new quota value.
+		newQuotaValue := subscription.GetCurrentQuotaValue(*resourceType.ID) - subscriptionAddon.Amount
+		if newQuotaValue < 0 {
+			newQuotaValue = 0
+		}
+
+		// Storage can't be reclaimed by lowering the quota, so don't drop it below the current usage unless forced to.
+		currentUsageValue := subscription.GetCurrentUsageValue(*resourceType.ID)
+		if !resourceType.Consumable && !force && newQuotaValue < currentUsageValue {
+			msg := fmt.Sprintf(
+				"removing the addon would reduce the %s quota to %f, which is below the current usage of %f",
+				resourceType.Name,
+				newQuotaValue,
+				currentUsageValue,
+			)
+			return rollback(ctx, msg, http.StatusConflict)
+		}
+
+		// Look up the update operation used to record the quota change.
+		updateOperation, err := db.GetUpdateOperation(context, tx, UpdateTypeSubtract)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		} else if updateOperation == nil {
+			msg := fmt.Sprintf("update operation %s not found", UpdateTypeSubtract)
+			return rollback(ctx, msg, http.StatusInternalServerError)
+		}
+
+		// Remove the subscription addon.
+		err = db.DeleteSubscriptionAddon(context, tx, subscriptionAddonID)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debug("removed the subscription addon")
+
+		// Lower the quota for the resource type associated with the addon and record the change.
+		opts := &quotas.UpdateOptions{Metadata: subscriptionAddonMetadata(subscriptionAddon), Actor: extractActor(ctx)}
+		newQuotaValue, err = quotas.Update(
+			context, tx, subscription, resourceType.ID, updateOperation, subscriptionAddon.Amount, opts,
+		)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		log.Debugf("lowered the %s quota to %f", resourceType.Name, newQuotaValue)
+
+		// Return the removed subscription addon along with the refund amount.
+		result := &model.SubscriptionAddonRemoval{
+			SubscriptionAddon: *subscriptionAddon,
+			RefundAmount:      subscriptionAddon.GetRefundAmount(),
+			Quota:             newQuotaValue,
+		}
+		return model.Success(ctx, result, http.StatusOK)
+	})
+	return transactionResult(err)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/cyverse/qms/internal/db"
 	"github.com/cyverse/qms/internal/model"
 	"github.com/cyverse/qms/internal/query"
+	"github.com/cyverse/qms/internal/quotas"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -57,6 +58,55 @@ func (sa *SubscriptionAdder) subscriptionErrorf(username string, f string, args
 	return sa.subscriptionError(username, fmt.Sprintf(f, args...))
 }
 
+// carryOverUsages copies the usage values for non-consumable resource types, such as data storage, from the previous
+// subscription into a new subscription. Each copied value is recorded as a SET update that takes effect when the new
+// subscription begins. Usage values for consumable resource types aren't copied because they start over with each
+// subscription. Be careful to ensure that all of the previous subscription's details have been loaded before calling
+// this function.
+func carryOverUsages(ctx context.Context, tx *gorm.DB, previous, subscription *model.Subscription) error {
+	wrapMsg := "unable to carry over the usages from the previous subscription"
+
+	// Determine which usage values to carry over.
+	var usages []model.Usage
+	for _, usage := range previous.Usages {
+		if !usage.ResourceType.Consumable && usage.Usage != 0 {
+			usages = append(usages, usage)
+		}
+	}
+	if len(usages) == 0 {
+		return nil
+	}
+
+	// Look up the update operation.
+	updateOperation, err := db.GetUpdateOperation(ctx, tx, UpdateTypeSet)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+	if updateOperation == nil {
+		return fmt.Errorf("%s: update operation %s not found", wrapMsg, UpdateTypeSet)
+	}
+
+	// Load the details of the new subscription.
+	details, err := db.GetSubscriptionDetails(ctx, tx, *subscription.ID)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	// Copy the usage values.
+	opts := &usageUpdateOptions{
+		Metadata:      model.Metadata{"previous_subscription_id": *previous.ID},
+		EffectiveDate: details.EffectiveStartDate,
+	}
+	for _, usage := range usages {
+		err = recordUsageUpdate(ctx, tx, details, &usage.ResourceType, updateOperation, usage.Usage, opts)
+		if err != nil {
+			return errors.Wrap(err, wrapMsg)
+		}
+	}
+
+	return nil
+}
+
 // AddSubscription subscribes a user to a subscription plan.
 func (sa *SubscriptionAdder) AddSubscription(tx *gorm.DB, req model.SubscriptionRequest) *model.SubscriptionResponse {
 	username := req.Username
@@ -86,6 +136,9 @@ func (sa *SubscriptionAdder) AddSubscription(tx *gorm.DB, req model.Subscription
 	if !ok || plan == nil {
 		return sa.subscriptionErrorf(*username, "plan does not exist: %s", *planName)
 	}
+	if plan.Archived {
+		return sa.subscriptionErrorf(*username, "plan has been archived: %s", *planName)
+	}
 
 	// Add some fields to the logger.
 	var log = sa.cfg.Log.WithFields(
@@ -105,14 +158,15 @@ func (sa *SubscriptionAdder) AddSubscription(tx *gorm.DB, req model.Subscription
 		return sa.subscriptionError(*username, err.Error())
 	}
 
-	// Check the current plan if we're supposed to.
-	if !sa.cfg.Force {
-		activeSubscription, err := db.GetActiveSubscriptionDetailsForDate(sa.cfg.Ctx, tx, *username, startDate)
-		if err != nil {
-			log.Error(err)
-			return sa.subscriptionError(*username, err.Error())
-		}
+	// Look up the subscription that's active as of the start date.
+	activeSubscription, err := db.GetActiveSubscriptionDetailsForDate(sa.cfg.Ctx, tx, *username, startDate)
+	if err != nil {
+		log.Error(err)
+		return sa.subscriptionError(*username, err.Error())
+	}
 
+	// Check the current plan if we're supposed to.
+	if !sa.cfg.Force && activeSubscription != nil {
 		// Compare the CPU allocations to determine the plan levels to determine if the user gets a new subscription.
 		activeCPUAllocation := activeSubscription.Plan.GetDefaultQuotaValue(model.RESOURCE_TYPE_CPU_HOURS)
 		newCPUAllocation := plan.GetDefaultQuotaValue(model.RESOURCE_TYPE_CPU_HOURS)
@@ -135,6 +189,23 @@ func (sa *SubscriptionAdder) AddSubscription(tx *gorm.DB, req model.Subscription
 		return sa.subscriptionError(*username, err.Error())
 	}
 
+	// Carry over the usage values for non-consumable resource types.
+	if activeSubscription != nil {
+		err = carryOverUsages(sa.cfg.Ctx, tx, activeSubscription, sub)
+		if err != nil {
+			log.Error(err)
+			return sa.subscriptionError(*username, err.Error())
+		}
+	}
+
+	// Carry over the quota overrides that extend into the new subscription.
+	actor := quotas.SystemActor
+	err = quotas.CarryOverOverrides(sa.cfg.Ctx, tx, sub, &actor, time.Now())
+	if err != nil {
+		log.Error(err)
+		return sa.subscriptionError(*username, err.Error())
+	}
+
 	// Load the subscription details.
 	sub, err = db.GetSubscriptionDetails(sa.cfg.Ctx, tx, *sub.ID)
 	if err != nil {
@@ -151,7 +222,7 @@ func (sa *SubscriptionAdder) AddSubscription(tx *gorm.DB, req model.Subscription
 //
 // # Add Subscriptions
 //
-// Creates the subscriptions described in the request body.
+// Creates the subscriptions described in the request body. Subscriptions to archived plans are rejected.
 //
 // Responses:
 //
@@ -201,6 +272,11 @@ func (s Server) AddSubscriptions(ctx echo.Context) error {
 				tx,
 				subscriptionRequest,
 			)
+
+			// Roll back any partial changes if the subscription couldn't be added.
+			if response[i].FailureReason != nil {
+				return errRollback
+			}
 			return nil
 		})
 	}
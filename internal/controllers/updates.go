@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cyverse-de/echo-middleware/v2/params"
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ListUpdates lists quota and usage updates, optionally filtered by metadata key and value.
+//
+// swagger:route GET /v1/updates updates listUpdates
+//
+// # List Updates
+//
+// Lists the quota and usage updates that match the query parameters. Updates can be found by metadata key alone, or
+// by metadata key and value. Metadata values are compared as text, so a value of `42` matches both the number `42` and
+// the string `"42"`. For example, all CPU hour charges for an analysis can be found by specifying the `usages` value
+// type, the `cpu.hours` resource type, the `analysis_id` metadata key, and the analysis ID as the metadata value. All
+// of the quota changes made by a bulk quota grant can be found by specifying the batch ID of the grant.
+//
+// Responses:
+//
+//	200: updateListingResponse
+//	400: badRequestResponse
+//	500: internalServerErrorResponse
+func (s Server) ListUpdates(ctx echo.Context) error {
+	var err error
+
+	log := log.WithFields(logrus.Fields{"context": "listing updates"})
+	context := ctx.Request().Context()
+
+	// Extract the query parameters.
+	var offset int32 = 0
+	offset, err = query.ValidateIntQueryParam(ctx, "offset", &offset, "gte=0")
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	var limit int32 = 50
+	limit, err = query.ValidateIntQueryParam(ctx, "limit", &limit, "gte=0")
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	sortDir, err := query.ValidateSortDir(ctx)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	valueType := ""
+	validValueTypes := []string{model.ValueTypeQuotas, model.ValueTypeUsages}
+	valueType, err = query.ValidateEnumQueryParam(ctx, "value-type", validValueTypes, &valueType)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	username := strings.TrimSuffix(ctx.QueryParam("username"), s.UsernameSuffix)
+	batchID, err := params.ValidatedQueryParam(ctx, "batch-id", "omitempty,uuid_rfc4122")
+	if err != nil {
+		return model.Error(ctx, "the batch ID must be a valid UUID", http.StatusBadRequest)
+	}
+
+	// A metadata value may only be specified along with a metadata key.
+	metadataKey := ctx.QueryParam("metadata-key")
+	var metadataValue *string
+	if ctx.QueryParams().Has("metadata-value") {
+		if metadataKey == "" {
+			msg := "the metadata-key query parameter is required when metadata-value is specified"
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+		value := ctx.QueryParam("metadata-value")
+		metadataValue = &value
+	}
+
+	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		params := &db.UpdateListingParams{
+			Offset:        int(offset),
+			Limit:         int(limit),
+			SortDir:       sortDir,
+			Username:      username,
+			ValueType:     valueType,
+			MetadataKey:   metadataKey,
+			MetadataValue: metadataValue,
+			BatchID:       batchID,
+		}
+
+		// Look up the resource type if one was specified.
+		resourceTypeName := ctx.QueryParam("resource-type")
+		if resourceTypeName != "" {
+			resourceType, err := db.GetResourceTypeByName(context, tx, resourceTypeName)
+			if err != nil {
+				log.Error(err)
+				return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			}
+			if resourceType == nil {
+				msg := fmt.Sprintf("resource type '%s' not found", resourceTypeName)
+				return model.Error(ctx, msg, http.StatusBadRequest)
+			}
+			params.ResourceTypeID = resourceType.ID
+		}
+
+		// Obtain the update listing.
+		updates, count, err := db.ListUpdates(context, tx, params)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		return model.Success(ctx, &model.UpdateListing{Updates: updates, Total: count}, http.StatusOK)
+	})
+}
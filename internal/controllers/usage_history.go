@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/ledger"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultUsageHistoryDays is the number of days of usage history returned if no start date is specified.
+const DefaultUsageHistoryDays = 30
+
+// GetUsageHistory returns the reconstructed usage history for a user.
+//
+// swagger:route GET /v1/usages/{username}/history usages getUsageHistory
+//
+// # Get Usage History
+//
+// Returns the usage values for a user at the end of each day, week, or month in a range of dates. The usage values are
+// reconstructed by replaying the usage updates recorded for the user, starting over from zero at the beginning of each
+// subscription. Weeks start on Monday. The end of the last interval is truncated to the end of the date range. Like
+// all other date query parameters, the dates are interpreted in the server's local time zone, so intervals begin at
+// local midnight and the date range ends at local midnight at the end of the last date.
+//
+// Responses:
+//
+//	200: usageHistoryResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) GetUsageHistory(ctx echo.Context) error {
+	var err error
+
+	log := log.WithFields(logrus.Fields{"context": "getting usage history"})
+	context := ctx.Request().Context()
+
+	// Extract the username.
+	username := strings.TrimSuffix(ctx.Param("username"), s.UsernameSuffix)
+	if username == "" {
+		return model.Error(ctx, "invalid username", http.StatusBadRequest)
+	}
+	log = log.WithFields(logrus.Fields{"user": username})
+
+	// Extract and validate the date range. Both ends of the date range are inclusive, so the range ends at midnight at
+	// the start of the day after the last date, in the server's local time zone.
+	today := time.Now()
+	defaultFrom := today.AddDate(0, 0, -DefaultUsageHistoryDays)
+	from, err := query.ValidateDateQueryParam(ctx, "from", &defaultFrom)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	to, err := query.ValidateDateQueryParam(ctx, "to", &today)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	until := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+
+	// Extract and validate the interval.
+	defaultInterval := ledger.IntervalDay
+	interval, err := query.ValidateEnumQueryParam(ctx, "interval", ledger.Intervals, &defaultInterval)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Divide the date range into buckets.
+	buckets, err := ledger.Buckets(from, until, interval)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Verify that the user exists.
+	err = s.ValidateUser(ctx, username)
+	if err != nil {
+		return nil
+	}
+
+	// Determine which resource types to include in the history.
+	var resourceTypes []*model.ResourceType
+	resourceTypeName := ctx.QueryParam("resource-type")
+	if resourceTypeName != "" {
+		resourceType, err := db.GetResourceTypeByName(context, s.GORMDB, resourceTypeName)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		if resourceType == nil {
+			msg := fmt.Sprintf("resource type '%s' not found", resourceTypeName)
+			return model.Error(ctx, msg, http.StatusBadRequest)
+		}
+		resourceTypes = append(resourceTypes, resourceType)
+	} else {
+		resourceTypeList, err := db.ListResourceTypes(context, s.GORMDB)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+		resourceTypes = resourceTypeList.ResourceTypes
+	}
+
+	// Usage values start over at the beginning of each subscription, so only the updates recorded since the start of
+	// the last subscription to begin before the date range need to be replayed.
+	resets, err := db.ListSubscriptionStartDates(context, s.GORMDB, username, until)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+	var since *time.Time
+	for i := range resets {
+		if resets[i].After(buckets[0].Start) {
+			break
+		}
+		since = &resets[i]
+	}
+
+	// Reconstruct the usage history for each resource type.
+	history := model.UsageHistory{
+		Username:      username,
+		Interval:      interval,
+		ResourceTypes: make([]model.ResourceTypeUsageHistory, len(resourceTypes)),
+	}
+	for i, resourceType := range resourceTypes {
+		updates, err := db.ListUsageUpdatesForUser(context, s.GORMDB, username, resourceType.ID, since, &until)
+		if err != nil {
+			log.Error(err)
+			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
+		values := ledger.Replay(updates, resets, buckets)
+		resourceTypeHistory := model.ResourceTypeUsageHistory{
+			ResourceType: *resourceType,
+			Buckets:      make([]model.UsageHistoryBucket, len(buckets)),
+		}
+		for j, bucket := range buckets {
+			resourceTypeHistory.Buckets[j] = model.UsageHistoryBucket{
+				Start: bucket.Start,
+				End:   bucket.End,
+				Usage: values[j],
+			}
+		}
+		history.ResourceTypes[i] = resourceTypeHistory
+	}
+
+	return model.Success(ctx, history, http.StatusOK)
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cyverse/qms/internal/ledger"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/internal/query"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// RebuildUsages compares the recorded usage values to the values obtained by replaying the usage updates.
+//
+// swagger:route POST /v1/usages/rebuild usages rebuildUsages
+//
+// # Rebuild Usage Values
+//
+// Replays the recorded usage updates for a single user or for every user and reports any recorded usage values that
+// don't agree with the replayed values. Each usage update is attributed to the subscription that was active at its
+// effective date. If the `apply` query parameter is set to `true` then the recorded usage values are replaced with the
+// replayed values. Otherwise, the recorded usage values are left unchanged.
+//
+// Responses:
+//
+//	200: usageRebuildResponse
+//	400: badRequestResponse
+//	404: notFoundResponse
+//	500: internalServerErrorResponse
+func (s Server) RebuildUsages(ctx echo.Context) error {
+	var err error
+
+	log := log.WithFields(logrus.Fields{"context": "rebuilding usages"})
+	context := ctx.Request().Context()
+
+	// Determine whether or not the replayed usage values should be applied.
+	defaultApply := false
+	apply, err := query.ValidateBooleanQueryParam(ctx, "apply", &defaultApply)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+
+	// Verify that the user exists if a username was specified.
+	username := strings.TrimSuffix(ctx.QueryParam("username"), s.UsernameSuffix)
+	if username != "" {
+		log = log.WithFields(logrus.Fields{"user": username})
+		err = s.ValidateUser(ctx, username)
+		if err != nil {
+			return nil
+		}
+	}
+
+	// Rebuild the usage values.
+	report, err := ledger.RebuildUsages(context, s.GORMDB, username, apply)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+
+	log.Infof("found %d drifted usage values; applied: %t", len(report.Drift), report.Applied)
+	return model.Success(ctx, report, http.StatusOK)
+}
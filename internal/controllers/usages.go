@@ -2,35 +2,54 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/httpmodel"
 	"github.com/cyverse/qms/internal/model"
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
-type Usage struct {
-	Username     string  `json:"username"`
-	ResourceName string  `json:"resource_name"`
-	UsageValue   float64 `json:"usage_value"`
-	UpdateType   string  `json:"update_type"`
-	Metadata     string  `json:"metadata"`
-}
-
 var (
 	ErrUserNotFound        = errors.New("user name not found")
 	ErrInvalidUsername     = errors.New("invalid username")
 	ErrInvalidResourceName = errors.New("invalid resource name")
 	ErrInvalidUsageValue   = errors.New("invalid usage value")
 	ErrInvalidUpdateType   = errors.New("invalid update type")
+
+	ErrIdempotencyKeyConflict = errors.New("the idempotency key has already been used for a different request")
+	ErrIdempotencyKeyMismatch = errors.New("the idempotency key in the request header doesn't match the request body")
+
+	ErrInvalidOriginalUpdateID  = errors.New("the original update ID must be a valid UUID")
+	ErrOriginalUpdateRequired   = errors.New("an original update ID is required for REVERSE updates")
+	ErrOriginalUpdateNotAllowed = errors.New("only SUBTRACT and REVERSE updates may refer to an original update")
+	ErrOriginalUpdateNotFound   = errors.New("original update not found")
+	ErrOriginalUpdateMismatch   = errors.New("the original update doesn't apply to the same user and resource type")
+	ErrUpdateNotReversible      = errors.New("the original update can't be reversed")
+	ErrUpdateAlreadyReversed    = errors.New("the original update has already been reversed")
+
+	ErrFutureEffectiveDate   = errors.New("the effective date may not be in the future")
+	ErrNoSubscriptionForDate = errors.New("the user had no active subscription at the effective date")
 )
 
+// validate is used to validate individual fields in usage update requests.
+var validate = validator.New()
+
+// IdempotencyKeyHeader is the name of the request header that may contain an idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is the name of the response header that indicates that a request was already processed.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
 func httpStatusCode(err error) int {
 	switch err {
 	case ErrUserNotFound:
@@ -43,41 +62,441 @@ func httpStatusCode(err error) int {
 		return http.StatusBadRequest
 	case ErrInvalidUpdateType:
 		return http.StatusBadRequest
+	case ErrIdempotencyKeyMismatch:
+		return http.StatusBadRequest
+	case ErrIdempotencyKeyConflict:
+		return http.StatusConflict
+	case ErrInvalidOriginalUpdateID, ErrOriginalUpdateRequired, ErrOriginalUpdateNotAllowed:
+		return http.StatusBadRequest
+	case ErrOriginalUpdateMismatch, ErrUpdateNotReversible:
+		return http.StatusBadRequest
+	case ErrOriginalUpdateNotFound:
+		return http.StatusNotFound
+	case ErrUpdateAlreadyReversed:
+		return http.StatusConflict
+	case ErrFutureEffectiveDate, ErrNoSubscriptionForDate, model.ErrInvalidMetadata:
+		return http.StatusBadRequest
 	default:
 		return http.StatusInternalServerError
 	}
 }
 
-func (s Server) addUsage(ctx context.Context, usage *Usage) error {
-	username := strings.TrimSuffix(usage.Username, s.UsernameSuffix)
+// recordQuotaThresholdEvent records an event in the outbox if a usage change pushed the usage for a resource type past
+// one of the quota thresholds. The event names the organization member or other user recorded in the update options
+// if there is one, and the subscription owner otherwise. Be careful to ensure that all of the subscription details have
+// been loaded before calling this function.
+func recordQuotaThresholdEvent(
+	ctx context.Context,
+	tx *gorm.DB,
+	subscription *model.Subscription,
+	opts *usageUpdateOptions,
+	resourceType *model.ResourceType,
+	previousUsage float64,
+	usage float64,
+) error {
+	quota := subscription.GetCurrentQuotaValue(*resourceType.ID)
+
+	// Determine whether or not a threshold was crossed.
+	threshold, crossed := model.GetCrossedQuotaThreshold(quota, previousUsage, usage)
+	if !crossed {
+		return nil
+	}
+
+	// Build the event payload.
+	event := model.QuotaThresholdEvent{
+		SubscriptionID:  *subscription.ID,
+		ResourceType:    resourceType.Name,
+		Unit:            resourceType.Unit,
+		Threshold:       threshold,
+		Quota:           quota,
+		PreviousUsage:   previousUsage,
+		Usage:           usage,
+		UsagePercentage: usage * 100 / quota,
+	}
+	switch {
+	case opts.Member != nil && opts.Member.User != nil:
+		event.Username = opts.Member.User.Username
+	case opts.User != nil:
+		event.Username = opts.User.Username
+	case subscription.User != nil:
+		event.Username = subscription.User.Username
+	}
+	if subscription.Organization != nil {
+		event.Organization = subscription.Organization.Name
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode the quota threshold event")
+	}
+
+	// Record the event.
+	outboxEvent := model.OutboxEvent{
+		EventType: model.OutboxEventTypeQuotaThreshold,
+		Payload:   string(payload),
+	}
+	err = db.SaveOutboxEvent(ctx, tx, &outboxEvent)
+	if err != nil {
+		return err
+	}
+	log.Debugf("recorded a quota threshold event for the %g%% threshold", threshold)
+
+	return nil
+}
+
+// usageUpdateOptions contains optional settings that are recorded along with a usage update.
+type usageUpdateOptions struct {
+	// Metadata to record with the update.
+	Metadata model.Metadata
+
+	// The idempotency key for the request that caused the update, if one was provided.
+	IdempotencyKey *string
+
+	// A hash of the request that caused the update, used to detect conflicting requests with the same idempotency key.
+	RequestHash *string
+
+	// The ID of the update that this update corrects, if any.
+	OriginalUpdateID *string
+
+	// The time at which the update takes effect. The current time is used if this field is nil.
+	EffectiveDate *time.Time
+
+	// The organization member whose usage is being recorded, for updates charged against an organization's pool.
+	Member *model.OrganizationMember
+
+	// The user whose usage is being recorded, for updates charged against an organization's pool on behalf of a user
+	// who no longer belongs to the organization.
+	User *model.User
+}
+
+// getEffectiveDate returns the time at which the update takes effect.
+func (o *usageUpdateOptions) getEffectiveDate() time.Time {
+	if o.EffectiveDate != nil {
+		return *o.EffectiveDate
+	}
+	return time.Now()
+}
+
+// usageUpdateOptionsFor returns the options to record with a usage update for the given request. An error is returned
+// if the metadata in the request isn't a JSON object.
+func usageUpdateOptionsFor(usage *httpmodel.Usage, username string) (*usageUpdateOptions, error) {
+	metadata, err := model.ParseMetadata(usage.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &usageUpdateOptions{Metadata: metadata, EffectiveDate: usage.GetEffectiveDate()}
+	if usage.IdempotencyKey != "" {
+		requestHash := usage.RequestHash(username)
+		opts.IdempotencyKey = &usage.IdempotencyKey
+		opts.RequestHash = &requestHash
+	}
+	return opts, nil
+}
+
+// checkIdempotencyKey determines whether or not a usage update with the same idempotency key has already been
+// recorded. Concurrent requests with the same idempotency key are serialized until the end of the current transaction.
+// ErrIdempotencyKeyConflict is returned if the idempotency key was already used for a different request.
+func checkIdempotencyKey(ctx context.Context, tx *gorm.DB, opts *usageUpdateOptions) (bool, error) {
+	if opts.IdempotencyKey == nil {
+		return false, nil
+	}
+
+	// Make sure that retries of the same request can't be processed concurrently.
+	err := db.LockIdempotencyKey(ctx, tx, *opts.IdempotencyKey)
+	if err != nil {
+		return false, err
+	}
+
+	// Look up the original update.
+	update, err := db.GetUpdateByIdempotencyKey(ctx, tx, *opts.IdempotencyKey)
+	if err != nil {
+		return false, err
+	}
+	if update == nil {
+		return false, nil
+	}
+
+	// The original request must match this one.
+	if update.RequestHash == nil || *update.RequestHash != *opts.RequestHash {
+		return false, ErrIdempotencyKeyConflict
+	}
+
+	return true, nil
+}
+
+// recordUsageUpdate applies a usage update to a subscription and records the update in the database. For updates
+// charged against an organization's pool, the update is applied to the amount that the member has consumed, and the
+// pool usage changes by the same amount. Concurrent changes to the usage for the same resource type are serialized, and
+// the current usage is read from the database once the lock has been obtained, so that no updates are lost. Be careful
+// to ensure that all of the subscription details have been loaded before calling this function.
+func recordUsageUpdate(
+	ctx context.Context,
+	tx *gorm.DB,
+	subscription *model.Subscription,
+	resourceType *model.ResourceType,
+	updateOperation *model.UpdateOperation,
+	value float64,
+	opts *usageUpdateOptions,
+) error {
+	log := log.WithFields(logrus.Fields{
+		"subscription": *subscription.ID,
+		"resource":     resourceType.Name,
+		"updateType":   updateOperation.Name,
+		"value":        value,
+	})
+
+	// Determine when the update takes effect.
+	effectiveDate := opts.getEffectiveDate()
+
+	// Prevent concurrent updates and reservation commits from overwriting each other's changes.
+	err := db.LockReservations(ctx, tx, *subscription.ID, *resourceType.ID)
+	if err != nil {
+		return err
+	}
+
+	// Determine the value that the update applies to.
+	currentUsageValue, err := db.GetUsageValue(ctx, tx, *subscription.ID, *resourceType.ID)
+	if err != nil {
+		return err
+	}
+	log.Debugf("the current usage value is %f", currentUsageValue)
+	previousValue := currentUsageValue
+	update := model.Update{
+		Value:             value,
+		ValueType:         model.ValueTypeUsages,
+		EffectiveDate:     effectiveDate,
+		UpdateOperationID: updateOperation.ID,
+		ResourceTypeID:    resourceType.ID,
+		UserID:            subscription.UserID,
+		OrganizationID:    subscription.OrganizationID,
+		Metadata:          opts.Metadata,
+		IdempotencyKey:    opts.IdempotencyKey,
+		RequestHash:       opts.RequestHash,
+		OriginalUpdateID:  opts.OriginalUpdateID,
+	}
+	if opts.User != nil {
+		update.UserID = opts.User.ID
+	}
+	if opts.Member != nil {
+		update.UserID = opts.Member.UserID
+		previousValue, err = db.GetOrganizationMemberUsage(
+			ctx, tx, *subscription.ID, *opts.Member.UserID, *resourceType.ID,
+		)
+		if err != nil {
+			return err
+		}
+		log.Debugf("the current member usage value is %f", previousValue)
+	}
+	update.PreviousValue = &previousValue
+	switch updateOperation.Name {
+	case UpdateTypeSet, UpdateTypeAdd, UpdateTypeSubtract, UpdateTypeReverse:
+	default:
+		return fmt.Errorf("invalid update type: %s", updateOperation.Name)
+	}
+	newValue := update.GetNewValue(updateOperation, previousValue)
+	update.NewValue = &newValue
+
+	// Determine the new usage value, updating the member's usage if the update is charged against an organization.
+	newUsageValue := newValue
+	if opts.Member != nil {
+		memberUsage := &model.OrganizationMemberUsage{
+			SubscriptionID: subscription.ID,
+			UserID:         opts.Member.UserID,
+			ResourceTypeID: resourceType.ID,
+			Usage:          newValue,
+		}
+		err = db.UpsertOrganizationMemberUsage(ctx, tx, memberUsage)
+		if err != nil {
+			return err
+		}
+		newUsageValue = math.Max(currentUsageValue+newValue-previousValue, 0)
+	}
+	log.Debugf("calculated the new usage to be %f", newUsageValue)
+
+	// Update the usage.
+	newUsage := &model.Usage{
+		SubscriptionID: subscription.ID,
+		ResourceTypeID: resourceType.ID,
+		Usage:          newUsageValue,
+	}
+	err = db.UpsertUsage(ctx, tx, newUsage)
+	if err != nil {
+		return errors.Wrap(err, "unable to update or insert the usage record")
+	}
+	log.Debug("added/updated the usage record in the database")
+
+	// Record an event if the update pushed the usage past one of the quota thresholds.
+	err = recordQuotaThresholdEvent(
+		ctx, tx, subscription, opts, resourceType, currentUsageValue, newUsageValue,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Record the update in the database.
+	err = tx.WithContext(ctx).Debug().Create(&update).Error
+	if err != nil {
+		return err
+	}
+	log.Debug("recorded the update in the databse")
+
+	return nil
+}
+
+// validateUsage validates a usage update request and returns the username with the username suffix removed.
+func validateUsage(usage *httpmodel.Usage, usernameSuffix string) (string, error) {
+	username := strings.TrimSuffix(usage.Username, usernameSuffix)
 	if username == "" {
-		return ErrInvalidUsername
+		return "", ErrInvalidUsername
 	}
 
 	if usage.ResourceName == "" {
-		return ErrInvalidResourceName
+		return "", ErrInvalidResourceName
 	}
 
 	if usage.UsageValue < 0 {
-		return ErrInvalidUsageValue
+		return "", ErrInvalidUsageValue
 	}
 
 	if usage.UpdateType == "" {
-		return ErrInvalidUpdateType
+		return "", ErrInvalidUpdateType
+	}
+
+	// Only corrections may refer to an original update, and reversals must do so.
+	isCorrection := usage.UpdateType == UpdateTypeSubtract || usage.UpdateType == UpdateTypeReverse
+	switch {
+	case usage.OriginalUpdateID != "" && validate.Var(usage.OriginalUpdateID, "uuid_rfc4122") != nil:
+		return "", ErrInvalidOriginalUpdateID
+	case usage.UpdateType == UpdateTypeReverse && usage.OriginalUpdateID == "":
+		return "", ErrOriginalUpdateRequired
+	case usage.OriginalUpdateID != "" && !isCorrection:
+		return "", ErrOriginalUpdateNotAllowed
+	}
+
+	// Back-dated updates are allowed, but updates that take effect in the future are not.
+	effectiveDate := usage.GetEffectiveDate()
+	if effectiveDate != nil && effectiveDate.After(time.Now()) {
+		return "", ErrFutureEffectiveDate
+	}
+
+	return username, nil
+}
+
+// prepareCorrection verifies that the original update referred to by a SUBTRACT or REVERSE usage update applies to the
+// same user and resource type, and links the new update to it. For REVERSE updates, the returned value is the change
+// made by the original update, which is the amount that gets subtracted from the current usage. For all other updates,
+// the requested usage value is returned.
+func prepareCorrection(
+	ctx context.Context,
+	tx *gorm.DB,
+	usage *httpmodel.Usage,
+	username string,
+	resourceType *model.ResourceType,
+	opts *usageUpdateOptions,
+) (float64, error) {
+	if usage.OriginalUpdateID == "" {
+		return usage.UsageValue, nil
+	}
+
+	// Look up and lock the original update so that it can't be reversed concurrently.
+	original, err := db.GetUpdate(ctx, tx, usage.OriginalUpdateID, true)
+	if err != nil {
+		return 0, err
+	}
+	if original == nil {
+		return 0, ErrOriginalUpdateNotFound
+	}
+
+	// The original update must be a usage update for the same user and resource type.
+	if original.ValueType != model.ValueTypeUsages ||
+		original.User.Username != username ||
+		*original.ResourceTypeID != *resourceType.ID {
+		return 0, ErrOriginalUpdateMismatch
+	}
+
+	// Link the new update to the original update.
+	opts.OriginalUpdateID = original.ID
+	opts.Metadata = opts.Metadata.With("original_update_id", *original.ID)
+
+	// There's nothing else to do unless this is a reversal.
+	if usage.UpdateType != UpdateTypeReverse {
+		return usage.UsageValue, nil
+	}
+
+	// Reversals can't be reversed, and updates can only be reversed once.
+	if original.UpdateOperation.Name == UpdateTypeReverse {
+		return 0, ErrUpdateNotReversible
+	}
+	reversed, err := db.CheckUpdateReversal(ctx, tx, *original.ID)
+	if err != nil {
+		return 0, err
+	}
+	if reversed {
+		return 0, ErrUpdateAlreadyReversed
+	}
+
+	// Determine the change made by the original update.
+	change, ok := original.GetChange(original.UpdateOperation)
+	if !ok {
+		return 0, ErrUpdateNotReversible
+	}
+
+	return change, nil
+}
+
+// addUsage applies a single usage update. The first return value is true if the update was already applied by an
+// earlier request with the same idempotency key, in which case nothing is changed.
+func (s Server) addUsage(ctx context.Context, usage *httpmodel.Usage) (bool, error) {
+	var replayed bool
+
+	username, err := validateUsage(usage, s.UsernameSuffix)
+	if err != nil {
+		return replayed, err
+	}
+	opts, err := usageUpdateOptionsFor(usage, username)
+	if err != nil {
+		return replayed, err
 	}
 
 	log.Debug("validated usage information")
 
-	log = log.WithFields(logrus.Fields{
+	log := log.WithFields(logrus.Fields{
 		"user":       username,
 		"resource":   usage.ResourceName,
 		"updateType": usage.UpdateType,
 		"value":      usage.UsageValue,
 	})
 
-	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
-		// Look up the currently active user plan, adding a default plan if one doesn't exist already.
-		subscription, err := db.GetActiveSubscriptionDetails(ctx, tx, username)
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+		// Don't apply the update again if it was already applied by an earlier request.
+		replayed, err = checkIdempotencyKey(ctx, tx, opts)
+		if err != nil || replayed {
+			return err
+		}
+
+		// Look up the subscription that the update applies to, which belongs to the user's organization if there is one.
+		var subscription *model.Subscription
+		pool, err := getOrganizationUsagePool(ctx, tx, username, opts.getEffectiveDate())
+		switch {
+		case err != nil:
+			return err
+		case pool != nil:
+			subscription = pool.subscription
+			opts.Member = pool.member
+		case opts.EffectiveDate == nil:
+			subscription, err = db.GetActiveSubscriptionDetails(ctx, tx, username)
+		default:
+			subscription, err = db.GetActiveSubscriptionDetailsForDate(ctx, tx, username, *opts.EffectiveDate)
+		}
+		if err != nil {
+			return err
+		}
+		if subscription == nil {
+			return ErrNoSubscriptionForDate
+		}
+
+		// Begin a new allotment period first if the current one has ended.
+		subscription, err = rolloverIfDue(ctx, tx, subscription, opts.getEffectiveDate())
 		if err != nil {
 			return err
 		}
@@ -95,67 +514,215 @@ func (s Server) addUsage(ctx context.Context, usage *Usage) error {
 		log.Debug("found resource type in database")
 
 		// Verify that the update operation for the given update type exists.
-		updateOperation := model.UpdateOperation{Name: usage.UpdateType}
-		err = tx.WithContext(ctx).Debug().First(&updateOperation).Error
-		if err == gorm.ErrRecordNotFound {
-			return errors.New("invalid update type")
-		}
+		updateOperation, err := db.GetUpdateOperation(ctx, tx, usage.UpdateType)
 		if err != nil {
 			return err
 		}
-		log.Debug("verified update operation from database")
-
-		// Determine the new usage value.
-		var newUsageValue float64
-		currentUsageValue := subscription.GetCurrentUsageValue(*resourceType.ID)
-		log.Debugf("the current usage value is %f", currentUsageValue)
-		switch usage.UpdateType {
-		case UpdateTypeSet:
-			newUsageValue = usage.UsageValue
-		case UpdateTypeAdd:
-			newUsageValue = currentUsageValue + usage.UsageValue
-		default:
-			return fmt.Errorf("invalid update type: %s", usage.UpdateType)
+		if updateOperation == nil {
+			return ErrInvalidUpdateType
 		}
-		log.Debugf("calculated the new usage to be %f", newUsageValue)
+		log.Debug("verified update operation from database")
 
-		// Update the usage.
-		newUsage := &model.Usage{
-			SubscriptionID: subscription.ID,
-			ResourceTypeID: resourceType.ID,
-			Usage:          newUsageValue,
-		}
-		err = db.UpsertUsage(ctx, tx, newUsage)
+		// Link corrections to the updates that they correct.
+		value, err := prepareCorrection(ctx, tx, usage, username, resourceType, opts)
 		if err != nil {
-			return errors.Wrap(err, "unable to update or insert the usage record")
+			return err
 		}
-		log.Debug("added/updated the usage record in the database")
 
-		// Record the update in the database.
-		update := model.Update{
-			Value:             usage.UsageValue,
-			ValueType:         model.ValueTypeUsages,
-			EffectiveDate:     time.Now(),
-			UpdateOperationID: updateOperation.ID,
-			ResourceTypeID:    resourceType.ID,
-			UserID:            subscription.UserID,
-			Metadata:          &usage.Metadata,
+		// Apply and record the update.
+		return recordUsageUpdate(ctx, tx, subscription, resourceType, updateOperation, value, opts)
+	})
+
+	return replayed, err
+}
+
+// UsageAdderConfig contains the configuration for a usage adder.
+type UsageAdderConfig struct {
+	Log            *logrus.Entry
+	Ctx            context.Context
+	UsernameSuffix string
+}
+
+// usagePoolKey identifies the usage pool that updates for a user with a given effective date are charged against. The
+// effective date is empty for updates that take effect immediately.
+type usagePoolKey struct {
+	username      string
+	effectiveDate string
+}
+
+// UsageAdder encapsulates the application of usage updates with cached indexes of resource types and update
+// operations. The usage pool that each user's updates are charged against is also cached, so that it only has to be
+// looked up once for each user and effective date in a batch.
+type UsageAdder struct {
+	cfg                    *UsageAdderConfig
+	resourceTypesByName    map[string]*model.ResourceType
+	updateOperationsByName map[string]*model.UpdateOperation
+	pools                  map[usagePoolKey]*usagePool
+}
+
+// NewUsageAdder creates a new UsageAdder instance.
+func NewUsageAdder(tx *gorm.DB, cfg *UsageAdderConfig) (*UsageAdder, error) {
+	resourceTypes, err := db.ListResourceTypes(cfg.Ctx, tx)
+	if err != nil {
+		err = errors.Wrap(err, "unable to load resource type information")
+		return nil, err
+	}
+	resourceTypesByName := make(map[string]*model.ResourceType, len(resourceTypes.ResourceTypes))
+	for _, resourceType := range resourceTypes.ResourceTypes {
+		resourceTypesByName[resourceType.Name] = resourceType
+	}
+
+	updateOperations, err := db.ListUpdateOperations(cfg.Ctx, tx)
+	if err != nil {
+		err = errors.Wrap(err, "unable to load update operation information")
+		return nil, err
+	}
+	updateOperationsByName := make(map[string]*model.UpdateOperation, len(updateOperations))
+	for _, updateOperation := range updateOperations {
+		updateOperationsByName[updateOperation.Name] = updateOperation
+	}
+
+	usageAdder := &UsageAdder{
+		cfg:                    cfg,
+		resourceTypesByName:    resourceTypesByName,
+		updateOperationsByName: updateOperationsByName,
+		pools:                  make(map[usagePoolKey]*usagePool),
+	}
+	return usageAdder, nil
+}
+
+// usageError returns a result record indicating that a usage update could not be applied.
+func (ua *UsageAdder) usageError(usage httpmodel.Usage, msg string) *httpmodel.UsageResult {
+	return &httpmodel.UsageResult{
+		Usage:         usage,
+		FailureReason: &msg,
+	}
+}
+
+// getUsagePool returns the usage pool that a usage update for a user is charged against, loading it if it hasn't been
+// cached yet. Pools that aren't owned by an organization contain only the usage details of the subscription.
+func (ua *UsageAdder) getUsagePool(tx *gorm.DB, username string, opts *usageUpdateOptions) (*usagePool, error) {
+	key := usagePoolKey{username: username}
+	if opts.EffectiveDate != nil {
+		key.effectiveDate = opts.EffectiveDate.Format(time.RFC3339Nano)
+	}
+	if pool, ok := ua.pools[key]; ok {
+		return pool, nil
+	}
+
+	// Look up the subscription that the update applies to, which belongs to the user's organization if there is one.
+	pool, err := getOrganizationUsagePool(ua.cfg.Ctx, tx, username, opts.getEffectiveDate())
+	if pool == nil && err == nil {
+		var subscription *model.Subscription
+		if opts.EffectiveDate == nil {
+			subscription, err = db.GetActiveSubscriptionUsageDetails(ua.cfg.Ctx, tx, username)
+		} else {
+			subscription, err = db.GetActiveSubscriptionUsageDetailsForDate(ua.cfg.Ctx, tx, username, *opts.EffectiveDate)
 		}
-		err = tx.WithContext(ctx).Debug().Create(&update).Error
-		if err != nil {
-			return err
+		if subscription != nil {
+			pool = &usagePool{subscription: subscription}
 		}
-		log.Debug("recorded the update in the databse")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil {
+		return nil, ErrNoSubscriptionForDate
+	}
 
-		return nil
-	})
+	ua.pools[key] = pool
+	return pool, nil
+}
+
+// ClearCachedPools discards the cached usage pools. This must be called whenever a transaction used to apply a usage
+// update is rolled back, because the cached pools may refer to subscriptions or allotment periods that were created in
+// that transaction.
+func (ua *UsageAdder) ClearCachedPools() {
+	ua.pools = make(map[usagePoolKey]*usagePool)
+}
+
+// AddUsage applies a single usage update.
+func (ua *UsageAdder) AddUsage(tx *gorm.DB, usage httpmodel.Usage) *httpmodel.UsageResult {
+	username, err := validateUsage(&usage, ua.cfg.UsernameSuffix)
+	if err != nil {
+		return ua.usageError(usage, err.Error())
+	}
+
+	// Look up the resource type.
+	resourceType, ok := ua.resourceTypesByName[usage.ResourceName]
+	if !ok {
+		return ua.usageError(usage, fmt.Sprintf("resource type '%s' does not exist", usage.ResourceName))
+	}
+
+	// Look up the update operation.
+	updateOperation, ok := ua.updateOperationsByName[usage.UpdateType]
+	if !ok {
+		return ua.usageError(usage, ErrInvalidUpdateType.Error())
+	}
+
+	// Add some fields to the logger.
+	var log = ua.cfg.Log.WithFields(
+		logrus.Fields{
+			"user":       username,
+			"resource":   usage.ResourceName,
+			"updateType": usage.UpdateType,
+			"value":      usage.UsageValue,
+		},
+	)
+
+	// Don't apply the update again if it was already applied by an earlier request.
+	opts, err := usageUpdateOptionsFor(&usage, username)
+	if err != nil {
+		return ua.usageError(usage, err.Error())
+	}
+	replayed, err := checkIdempotencyKey(ua.cfg.Ctx, tx, opts)
+	if err != nil {
+		log.Error(err)
+		return ua.usageError(usage, err.Error())
+	}
+	if replayed {
+		return &httpmodel.UsageResult{Usage: usage, Success: true, Replayed: true}
+	}
+
+	// Look up the usage pool that the update is charged against.
+	pool, err := ua.getUsagePool(tx, username, opts)
+	if err != nil {
+		log.Error(err)
+		return ua.usageError(usage, err.Error())
+	}
+	opts.Member = pool.member
+
+	// Begin a new allotment period first if the current one has ended.
+	pool.subscription, err = rolloverIfDue(ua.cfg.Ctx, tx, pool.subscription, opts.getEffectiveDate())
+	if err != nil {
+		log.Error(err)
+		return ua.usageError(usage, err.Error())
+	}
+	subscription := pool.subscription
+
+	// Link corrections to the updates that they correct.
+	value, err := prepareCorrection(ua.cfg.Ctx, tx, &usage, username, resourceType, opts)
+	if err != nil {
+		log.Error(err)
+		return ua.usageError(usage, err.Error())
+	}
+
+	// Apply and record the update.
+	err = recordUsageUpdate(ua.cfg.Ctx, tx, subscription, resourceType, updateOperation, value, opts)
+	if err != nil {
+		log.Error(err)
+		return ua.usageError(usage, err.Error())
+	}
+
+	return &httpmodel.UsageResult{Usage: usage, Success: true}
 }
 
-// AddUsages adds or updates the usage record for a user, plan, and resource type.
+// AddUsages adds or updates the usage record for a user, plan, and resource type. Clients may supply an idempotency
+// key in either the Idempotency-Key request header or the request body so that the request can be safely retried.
 func (s Server) AddUsages(ctx echo.Context) error {
 	var (
 		err   error
-		usage Usage
+		usage httpmodel.Usage
 	)
 
 	log := log.WithFields(logrus.Fields{"context": "adding usage information"})
@@ -167,12 +734,25 @@ func (s Server) AddUsages(ctx echo.Context) error {
 		return model.Error(ctx, "invalid request body", http.StatusBadRequest)
 	}
 
+	// The idempotency key may be specified in either the request header or the request body.
+	if headerKey := ctx.Request().Header.Get(IdempotencyKeyHeader); headerKey != "" {
+		if usage.IdempotencyKey != "" && usage.IdempotencyKey != headerKey {
+			return model.Error(ctx, ErrIdempotencyKeyMismatch.Error(), httpStatusCode(ErrIdempotencyKeyMismatch))
+		}
+		usage.IdempotencyKey = headerKey
+	}
+
 	log.Debugf("validated usage information %+v", usage)
 
-	if err = s.addUsage(context, &usage); err != nil {
+	replayed, err := s.addUsage(context, &usage)
+	if err != nil {
 		log.Error(err)
 		return model.Error(ctx, err.Error(), httpStatusCode(err))
 	}
+	if replayed {
+		log.Debugf("the usage update was already applied for idempotency key %s", usage.IdempotencyKey)
+		ctx.Response().Header().Set(IdempotentReplayedHeader, "true")
+	}
 
 	log.Debugf("added usage inforamtion %+v", usage)
 	username := strings.TrimSuffix(usage.Username, s.UsernameSuffix)
@@ -181,6 +761,78 @@ func (s Server) AddUsages(ctx echo.Context) error {
 	return model.SuccessMessage(ctx, successMsg, http.StatusOK)
 }
 
+// AddUsagesBatch applies multiple usage updates.
+//
+// swagger:route POST /v1/usages/batch usages addUsagesBatch
+//
+// # Apply Multiple Usage Updates
+//
+// Applies each of the usage updates in the request body in its own transaction, so that a failure to apply one update
+// doesn't prevent the others from being applied. The response contains a result for each update, in the same order as
+// the request, indicating whether or not the update was applied. Requests containing more than the configured maximum
+// number of updates are rejected.
+//
+// Responses:
+//
+//	200: usageBatchResponse
+//	400: badRequestResponse
+//	500: internalServerErrorResponse
+func (s Server) AddUsagesBatch(ctx echo.Context) error {
+	var err error
+
+	// Initialize the context for the endpoint.
+	var log = log.WithField("context", "adding usage information in bulk")
+	var context = ctx.Request().Context()
+
+	// Parse the request body.
+	var body httpmodel.UsageBatch
+	err = ctx.Bind(&body)
+	if err != nil {
+		msg := fmt.Sprintf("invalid request body: %s", err)
+		log.Error(msg)
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+	if len(body.Usages) > s.UsageBatchLimit {
+		msg := fmt.Sprintf("the request may contain at most %d usage updates", s.UsageBatchLimit)
+		log.Error(msg)
+		return model.Error(ctx, msg, http.StatusBadRequest)
+	}
+
+	// Create a new usage adder.
+	uaConfig := &UsageAdderConfig{
+		Log:            log,
+		Ctx:            context,
+		UsernameSuffix: s.UsernameSuffix,
+	}
+	usageAdder, err := NewUsageAdder(s.GORMDB, uaConfig)
+	if err != nil {
+		log.Error(err)
+		return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+	}
+
+	// Apply each usage update in a separate transaction.
+	response := make([]*httpmodel.UsageResult, len(body.Usages))
+	for i, usage := range body.Usages {
+		err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
+			response[i] = usageAdder.AddUsage(tx, usage)
+			if !response[i].Success {
+				return errors.New(*response[i].FailureReason)
+			}
+			return nil
+		})
+		if err != nil && response[i].Success {
+			msg := err.Error()
+			response[i].Success = false
+			response[i].FailureReason = &msg
+		}
+		if err != nil {
+			usageAdder.ClearCachedPools()
+		}
+	}
+
+	return model.Success(ctx, response, http.StatusOK)
+}
+
 func (s Server) userUpdates(ctx context.Context, username string) ([]model.Update, error) {
 	var err error
 
@@ -212,7 +864,8 @@ func (s Server) GetAllUsageOfUser(ctx echo.Context) error {
 
 	log = log.WithFields(logrus.Fields{"user": username})
 
-	subscription, err := db.GetActiveSubscriptionDetails(context, s.GORMDB, username)
+	// Members of an organization with an active subscription share the organization's usage pool.
+	pool, err := getUsagePool(context, s.GORMDB, username)
 	if err != nil {
 		sCode := httpStatusCode(err)
 		log.Error(err)
@@ -221,7 +874,7 @@ func (s Server) GetAllUsageOfUser(ctx echo.Context) error {
 
 	log.Info("successfully found usages")
 
-	return model.Success(ctx, subscription.Usages, http.StatusOK)
+	return model.Success(ctx, pool.subscription.Usages, http.StatusOK)
 }
 
 func (s Server) GetAllUsageUpdatesForUser(ctx echo.Context) error {
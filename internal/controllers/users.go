@@ -13,13 +13,16 @@ import (
 	"github.com/cyverse/qms/internal/model"
 	"github.com/cyverse/qms/internal/model/timestamp"
 	"github.com/cyverse/qms/internal/query"
+	"github.com/cyverse/qms/internal/quotas"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	UpdateTypeSet = "SET"
-	UpdateTypeAdd = "ADD"
+	UpdateTypeSet      = model.UpdateOperationSet
+	UpdateTypeAdd      = model.UpdateOperationAdd
+	UpdateTypeSubtract = model.UpdateOperationSubtract
+	UpdateTypeReverse  = model.UpdateOperationReverse
 )
 
 // swagger:route GET /v1/users users listUsers
@@ -90,7 +93,10 @@ func (s Server) GetSubscriptionDetails(ctx echo.Context) error {
 // Update Current Subscription Plan Quota
 //
 // Updates the current quota for the given username and resource type. If the user doesn't have an active
-// subscription then a new subscription for the default subscription plan type will be created.
+// subscription then a new subscription for the default subscription plan type will be created. The quota change is
+// recorded in the updates table, along with the value of the `actor` query parameter if it's specified. The requested
+// value is the base quota; any active quota overrides for the resource type are added on top of it, and they're removed
+// again when the overrides are retired.
 //
 // responses:
 //   200: subscriptionsResponse
@@ -136,56 +142,70 @@ func (s Server) UpdateCurrentSubscriptionQuota(c echo.Context) error {
 	}
 
 	// Start a transaction.
-	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
 		// Look up the resource type.
 		resourceType, err := db.GetResourceTypeByName(ctx, tx, resourceTypeName)
 		if err != nil {
 			log.Error(err)
-			return model.Error(c, err.Error(), http.StatusInternalServerError)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
 		}
 		if resourceType == nil {
 			msg := fmt.Sprintf("resource type '%s' not found", resourceTypeName)
 			log.Error(msg)
-			return model.Error(c, msg, http.StatusBadRequest)
+			return rollback(c, msg, http.StatusBadRequest)
 		}
 
 		// Determine whether or not the user has an active subscription.
 		hasActiveSubscription, err := db.HasActiveSubscription(ctx, tx, username)
 		if err != nil {
 			log.Error(err)
-			return model.Error(c, err.Error(), http.StatusInternalServerError)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
 		}
 
 		// Load the user's current subscription, creating a new subscription if necessary.
-		subcription, err := db.GetActiveSubscription(ctx, tx, username)
+		subscription, err := db.GetActiveSubscriptionDetails(ctx, tx, username)
 		if err != nil {
 			log.Error(err)
-			return model.Error(c, err.Error(), http.StatusInternalServerError)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
 		}
 
-		// Insert or update the quota.
-		quota := &model.Quota{
-			SubscriptionID: subcription.ID,
-			Quota:          body.Quota,
-			ResourceTypeID: resourceType.ID,
+		// Look up the update operation used to record the quota change.
+		updateOperation, err := db.GetUpdateOperation(ctx, tx, UpdateTypeSet)
+		if err != nil {
+			log.Error(err)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
+		} else if updateOperation == nil {
+			msg := fmt.Sprintf("update operation %s not found", UpdateTypeSet)
+			return rollback(c, msg, http.StatusInternalServerError)
 		}
-		err = db.UpsertQuota(ctx, tx, quota)
+
+		// Active quota overrides remain in effect on top of the new base quota.
+		overrideTotal, err := db.GetActiveQuotaOverrideTotal(ctx, tx, *subscription.ID, *resourceType.ID)
 		if err != nil {
 			log.Error(err)
-			return model.Error(c, err.Error(), http.StatusInternalServerError)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
+		}
+
+		// Insert or update the quota.
+		opts := &quotas.UpdateOptions{Actor: extractActor(c)}
+		_, err = quotas.Update(ctx, tx, subscription, resourceType.ID, updateOperation, body.Quota+overrideTotal, opts)
+		if err != nil {
+			log.Error(err)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
 		}
 
 		// Load the subscription details.
-		details, err := db.GetSubscriptionDetails(ctx, tx, *subcription.ID)
+		details, err := db.GetSubscriptionDetails(ctx, tx, *subscription.ID)
 		if err != nil {
 			log.Error(err)
-			return model.Error(c, err.Error(), http.StatusInternalServerError)
+			return rollback(c, err.Error(), http.StatusInternalServerError)
 		}
 
 		// Return the response.
 		responseBody := model.SubscriptionResponseFromSubscription(details, !hasActiveSubscription)
 		return model.Success(c, responseBody, http.StatusOK)
 	})
+	return transactionResult(err)
 }
 
 // AddUser adds a new user to the database. This is a no-op if the user already
@@ -234,7 +254,10 @@ func (s Server) AddUser(ctx echo.Context) error {
 //
 // # Subscribe a User to a New Plan
 //
-// Creates a new subscription for the user with the given username.
+// Creates a new subscription for the user with the given username. Consumable quotas are multiplied by the number of
+// periods in the subscription unless the `allotment-mode` query parameter is set to `true`, in which case consumable
+// quotas cover a single year and consumable usages are reset at each yearly anniversary of the subscription start date.
+// Users may not be subscribed to archived plans.
 //
 // Responses:
 //   200: subscription
@@ -272,6 +295,13 @@ func (s Server) UpdateSubscription(ctx echo.Context) error {
 	}
 	log.Debugf("periods from request is %d", periods)
 
+	defaultAllotmentMode := false
+	allotmentMode, err := query.ValidateBooleanQueryParam(ctx, "allotment-mode", &defaultAllotmentMode)
+	if err != nil {
+		return model.Error(ctx, err.Error(), http.StatusBadRequest)
+	}
+	log.Debugf("allotment mode flag from request is %t", allotmentMode)
+
 	defaultStartDate := time.Now()
 	startDate, err := query.ValidateDateQueryParam(ctx, "start-date", &defaultStartDate)
 	if err != nil {
@@ -302,31 +332,42 @@ func (s Server) UpdateSubscription(ctx echo.Context) error {
 	})
 
 	// Start a transaction.
-	return s.GORMDB.Transaction(func(tx *gorm.DB) error {
+	err = s.GORMDB.Transaction(func(tx *gorm.DB) error {
 		var err error
 
 		// Either add the user to the database or look up the existing user information.
 		user, err := db.GetUser(context, tx, username)
 		if err != nil {
-			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
 		}
 		log.Debug("found user in the database")
 
 		// Verify that a plan with the given name exists.
 		plan, err := db.GetPlan(context, tx, planName)
 		if err != nil {
-			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
 		}
 		if plan == nil {
 			msg := fmt.Sprintf("plan name `%s` not found", planName)
-			return model.Error(ctx, msg, http.StatusBadRequest)
+			return rollback(ctx, msg, http.StatusBadRequest)
+		}
+		if plan.Archived {
+			msg := fmt.Sprintf("plan name `%s` has been archived", planName)
+			return rollback(ctx, msg, http.StatusBadRequest)
 		}
 		log.Debug("verified that plan exists in database")
 
+		// Look up the subscription that's active as of the start date so that usages can be carried over.
+		activeSubscription, err := db.GetActiveSubscriptionDetailsForDate(context, tx, username, startDate)
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
 		// Deactivate conflicting subscriptions for the user.
 		err = db.DeactivateSubscriptions(context, tx, *user.ID, startDate, endDate)
 		if err != nil {
-			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
 		}
 		log.Debug("deactivated conflicting subscriptions for the user")
 
@@ -334,29 +375,48 @@ func (s Server) UpdateSubscription(ctx echo.Context) error {
 		startTimestamp := timestamp.Timestamp(startDate)
 		endTimestamp := timestamp.Timestamp(endDate)
 		opts := &model.SubscriptionOptions{
-			Paid:      &paid,
-			Periods:   &periods,
-			StartDate: &startTimestamp,
-			EndDate:   &endTimestamp,
+			Paid:          &paid,
+			Periods:       &periods,
+			StartDate:     &startTimestamp,
+			EndDate:       &endTimestamp,
+			AllotmentMode: &allotmentMode,
 		}
 
 		// Subscribe the user to the plan.
 		subscription, err := db.SubscribeUserToPlan(context, tx, user, plan, opts)
 		if err != nil {
-			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
 		}
 		log.Debug("finished adding the new subscription")
 
+		// Carry over the usage values for non-consumable resource types.
+		if activeSubscription != nil {
+			err = carryOverUsages(context, tx, activeSubscription, subscription)
+			if err != nil {
+				log.Error(err)
+				return rollback(ctx, err.Error(), http.StatusInternalServerError)
+			}
+			log.Debug("carried over the usages from the previous subscription")
+		}
+
+		// Carry over the quota overrides that extend into the new subscription.
+		err = quotas.CarryOverOverrides(context, tx, subscription, extractActor(ctx), time.Now())
+		if err != nil {
+			log.Error(err)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
+		}
+
 		// Load the subscription details.
 		details, err := db.GetSubscriptionDetails(context, tx, *subscription.ID)
 		if err != nil {
 			log.Error(err)
-			return model.Error(ctx, err.Error(), http.StatusInternalServerError)
+			return rollback(ctx, err.Error(), http.StatusInternalServerError)
 		}
 
 		// Return the response.
 		return model.Success(ctx, details, http.StatusOK)
 	})
+	return transactionResult(err)
 }
 
 // ListUserSubscriptions is the handler for the GET /v1/subscriptions/:username endpoint.
@@ -31,3 +31,26 @@ func (s Server) ValidateUser(ctx echo.Context, username string) error {
 	}
 	return nil
 }
+
+// errRollback is returned by transaction callbacks that have already sent an error response to the caller, so that the
+// transaction is rolled back without attempting to send a second response.
+var errRollback = errors.New("the transaction was rolled back")
+
+// rollback sends an error response to the caller and returns an error that causes the enclosing transaction to be
+// rolled back. Handlers that use this function must pass the result of the transaction through transactionResult.
+func rollback(ctx echo.Context, msg string, status int) error {
+	err := model.Error(ctx, msg, status)
+	if err != nil {
+		return err
+	}
+	return errRollback
+}
+
+// transactionResult converts the error returned by a transaction into the error returned by a handler. Errors caused by
+// calls to rollback are discarded because the error response has already been sent.
+func transactionResult(err error) error {
+	if errors.Is(err, errRollback) {
+		return nil
+	}
+	return err
+}
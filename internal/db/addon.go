@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// addonDetails adds the clauses required to load the details of an addon to a query.
+func addonDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("ResourceType").
+		Preload("AddonRates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("effective_date asc")
+		})
+}
+
+// ListAddons lists all of the addons that are currently defined.
+func ListAddons(ctx context.Context, db *gorm.DB) ([]*model.Addon, error) {
+	wrapMsg := "unable to list addons"
+	var err error
+
+	var addons []*model.Addon
+	err = addonDetails(db.WithContext(ctx)).
+		Order("name asc").
+		Find(&addons).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return addons, nil
+}
+
+// GetAddonByID looks up the addon with the given identifier.
+func GetAddonByID(ctx context.Context, db *gorm.DB, addonID string) (*model.Addon, error) {
+	wrapMsg := fmt.Sprintf("unable to look up addon ID '%s'", addonID)
+	var err error
+
+	addon := model.Addon{ID: &addonID}
+	err = addonDetails(db.WithContext(ctx)).First(&addon).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &addon, nil
+}
+
+// GetAddonByName looks up the addon with the given name.
+func GetAddonByName(ctx context.Context, db *gorm.DB, name string) (*model.Addon, error) {
+	wrapMsg := fmt.Sprintf("unable to look up addon name '%s'", name)
+	var err error
+
+	var addon model.Addon
+	err = addonDetails(db.WithContext(ctx)).Where("name = ?", name).First(&addon).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &addon, nil
+}
+
+// CheckAddonExistence determines whether or not an addon with the given identifier exists.
+func CheckAddonExistence(ctx context.Context, db *gorm.DB, addonID string) (bool, error) {
+	wrapMsg := fmt.Sprintf("unable to look up addon ID '%s'", addonID)
+	var err error
+
+	var exists bool
+	err = db.WithContext(ctx).
+		Model(&model.Addon{}).
+		Select("count(*) > 0").
+		Where("id = ?", addonID).
+		Find(&exists).
+		Error
+	if err != nil {
+		return false, errors.Wrap(err, wrapMsg)
+	}
+
+	return exists, nil
+}
+
+// GetActiveAddonRate returns the currently active rate for an addon. A nil pointer is returned if the addon doesn't
+// have an active rate.
+func GetActiveAddonRate(ctx context.Context, db *gorm.DB, addonID string) (*model.AddonRate, error) {
+	wrapMsg := fmt.Sprintf("unable to look up the active addon rate for '%s'", addonID)
+	var err error
+
+	var addonRate model.AddonRate
+	err = db.WithContext(ctx).
+		Where("addon_id = ?", addonID).
+		Where("effective_date <= CURRENT_TIMESTAMP").
+		Order("effective_date desc").
+		First(&addonRate).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &addonRate, nil
+}
+
+// SaveAddonRates saves new rates for an existing addon.
+func SaveAddonRates(ctx context.Context, db *gorm.DB, addonRates []model.AddonRate) error {
+	wrapMsg := "unable to save the addon rates"
+
+	err := db.WithContext(ctx).Create(addonRates).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// UpdateAddon updates the descriptive fields of an existing addon. The addon rates are left untouched.
+func UpdateAddon(ctx context.Context, db *gorm.DB, addon *model.Addon) error {
+	wrapMsg := "unable to update addon"
+
+	// Make sure that the incoming addon has an identifier associated with it.
+	if addon.ID == nil || *addon.ID == "" {
+		return fmt.Errorf("%s: no addon ID specified", wrapMsg)
+	}
+
+	err := db.WithContext(ctx).
+		Model(addon).
+		Select("Name", "Description", "ResourceTypeID", "DefaultAmount", "DefaultPaid").
+		Updates(addon).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// CheckAddonUsage determines whether or not an addon has been applied to any subscriptions.
+func CheckAddonUsage(ctx context.Context, db *gorm.DB, addonID string) (bool, error) {
+	wrapMsg := fmt.Sprintf("unable to determine whether addon '%s' has been applied to subscriptions", addonID)
+	var err error
+
+	var inUse bool
+	err = db.WithContext(ctx).
+		Table("subscription_addons").
+		Select("count(*) > 0").
+		Where("addon_id = ?", addonID).
+		Find(&inUse).
+		Error
+	if err != nil {
+		return false, errors.Wrap(err, wrapMsg)
+	}
+
+	return inUse, nil
+}
+
+// DeleteAddon removes an addon and its rates from the database.
+func DeleteAddon(ctx context.Context, db *gorm.DB, addonID string) error {
+	wrapMsg := fmt.Sprintf("unable to delete addon '%s'", addonID)
+
+	err := db.WithContext(ctx).Delete(&model.Addon{ID: &addonID}).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListSubscriptionsDueForRollover lists subscriptions in allotment mode whose current allotment periods ended at or
+// before the given time, locking them until the end of the current transaction. Subscriptions whose final periods have
+// ended aren't included, and subscriptions that are already locked by another transaction are skipped.
+func ListSubscriptionsDueForRollover(
+	ctx context.Context, db *gorm.DB, now time.Time, limit int,
+) ([]*model.Subscription, error) {
+	wrapMsg := "unable to list the subscriptions that are due for an allotment period rollover"
+	var err error
+
+	var subscriptions []*model.Subscription
+	err = db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("allotment_mode").
+		Where("current_period_end <= ?", now).
+		Where("(effective_end_date IS NULL OR current_period_end < effective_end_date)").
+		Order("current_period_end asc").
+		Limit(limit).
+		Find(&subscriptions).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return subscriptions, nil
+}
+
+// LockSubscriptionForRollover locks a subscription until the end of the current transaction so that its allotment
+// period can't be rolled over by another transaction at the same time.
+func LockSubscriptionForRollover(ctx context.Context, db *gorm.DB, subscriptionID string) error {
+	wrapMsg := fmt.Sprintf("unable to lock subscription '%s'", subscriptionID)
+
+	var subscription model.Subscription
+	err := db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", subscriptionID).
+		First(&subscription).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// UpdateSubscriptionPeriod records the start and end of the current allotment period of a subscription.
+func UpdateSubscriptionPeriod(ctx context.Context, db *gorm.DB, subscription *model.Subscription) error {
+	wrapMsg := fmt.Sprintf("unable to update the allotment period of subscription '%s'", *subscription.ID)
+
+	err := db.WithContext(ctx).
+		Model(subscription).
+		Select("CurrentPeriodStart", "CurrentPeriodEnd").
+		Updates(subscription).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// SaveSubscriptionPeriod records the final quota and usage values for a resource type in a completed allotment period.
+func SaveSubscriptionPeriod(ctx context.Context, db *gorm.DB, period *model.SubscriptionPeriod) error {
+	wrapMsg := "unable to save the subscription period"
+
+	err := db.WithContext(ctx).Omit("ResourceType").Create(period).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// ListSubscriptionPeriods lists the completed allotment periods of a subscription, oldest first.
+func ListSubscriptionPeriods(
+	ctx context.Context, db *gorm.DB, subscriptionID string,
+) ([]*model.SubscriptionPeriod, error) {
+	wrapMsg := fmt.Sprintf("unable to list the allotment periods of subscription '%s'", subscriptionID)
+	var err error
+
+	periods := make([]*model.SubscriptionPeriod, 0)
+	err = db.WithContext(ctx).
+		Preload("ResourceType").
+		Joins("JOIN resource_types ON subscription_periods.resource_type_id = resource_types.id").
+		Where("subscription_periods.subscription_id = ?", subscriptionID).
+		Order("subscription_periods.period_start asc, resource_types.name asc").
+		Find(&periods).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return periods, nil
+}
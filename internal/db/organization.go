@@ -0,0 +1,318 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveOrganization records a new organization in the database.
+func SaveOrganization(ctx context.Context, db *gorm.DB, organization *model.Organization) error {
+	wrapMsg := "unable to save the organization"
+
+	err := db.WithContext(ctx).Omit("Members").Create(organization).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// GetOrganization looks up the organization with the given identifier, along with its members. A nil pointer is
+// returned if the organization doesn't exist.
+func GetOrganization(ctx context.Context, db *gorm.DB, organizationID string) (*model.Organization, error) {
+	wrapMsg := fmt.Sprintf("unable to look up organization '%s'", organizationID)
+	var err error
+
+	var organization model.Organization
+	err = db.WithContext(ctx).
+		Preload("Members", func(db *gorm.DB) *gorm.DB {
+			return db.
+				Joins("INNER JOIN users ON organization_members.user_id = users.id").
+				Order("users.username asc")
+		}).
+		Preload("Members.User").
+		Preload("Members.Caps").
+		Preload("Members.Caps.ResourceType").
+		Where("id = ?", organizationID).
+		First(&organization).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &organization, nil
+}
+
+// GetOrganizationByName looks up the organization with the given name. A nil pointer is returned if the organization
+// doesn't exist.
+func GetOrganizationByName(ctx context.Context, db *gorm.DB, name string) (*model.Organization, error) {
+	wrapMsg := fmt.Sprintf("unable to look up organization '%s'", name)
+	var err error
+
+	var organization model.Organization
+	err = db.WithContext(ctx).Where("name = ?", name).First(&organization).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &organization, nil
+}
+
+// ListOrganizations lists all of the organizations in the database, sorted by name.
+func ListOrganizations(ctx context.Context, db *gorm.DB) ([]*model.Organization, error) {
+	wrapMsg := "unable to list organizations"
+	var err error
+
+	organizations := make([]*model.Organization, 0)
+	err = db.WithContext(ctx).Order("name asc").Find(&organizations).Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return organizations, nil
+}
+
+// GetOrganizationMembership looks up the organization membership for the user with the given username, along with the
+// organization and the member's caps. A nil pointer is returned if the user isn't a member of any organization.
+func GetOrganizationMembership(ctx context.Context, db *gorm.DB, username string) (*model.OrganizationMember, error) {
+	wrapMsg := fmt.Sprintf("unable to look up the organization membership for user '%s'", username)
+	var err error
+
+	var member model.OrganizationMember
+	err = db.WithContext(ctx).
+		Joins("JOIN users ON organization_members.user_id = users.id").
+		Preload("Organization").
+		Preload("User").
+		Preload("Caps").
+		Preload("Caps.ResourceType").
+		Where("users.username = ?", username).
+		First(&member).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &member, nil
+}
+
+// GetOrganizationMembershipForUserID looks up the organization membership for the user with the given ID. A nil pointer
+// is returned if the user isn't a member of any organization.
+func GetOrganizationMembershipForUserID(
+	ctx context.Context, db *gorm.DB, userID string,
+) (*model.OrganizationMember, error) {
+	wrapMsg := fmt.Sprintf("unable to look up the organization membership for user ID '%s'", userID)
+	var err error
+
+	var member model.OrganizationMember
+	err = db.WithContext(ctx).
+		Preload("Organization").
+		Preload("User").
+		Preload("Caps").
+		Where("user_id = ?", userID).
+		First(&member).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &member, nil
+}
+
+// SaveOrganizationMember records a new organization membership or updates the role of an existing one.
+func SaveOrganizationMember(ctx context.Context, db *gorm.DB, member *model.OrganizationMember) error {
+	wrapMsg := "unable to save the organization member"
+
+	err := db.WithContext(ctx).
+		Omit("Organization", "User", "Caps").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).
+		Create(member).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// DeleteOrganizationMember removes an organization membership from the database, along with the member's caps. The
+// amounts that the member consumed from the organization's pool are retained.
+func DeleteOrganizationMember(ctx context.Context, db *gorm.DB, memberID string) error {
+	wrapMsg := fmt.Sprintf("unable to delete organization member '%s'", memberID)
+
+	err := db.WithContext(ctx).Where("id = ?", memberID).Delete(&model.OrganizationMember{}).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// UpsertOrganizationMemberCap sets the limit on the amount of a resource that an organization member may consume.
+func UpsertOrganizationMemberCap(ctx context.Context, db *gorm.DB, memberCap *model.OrganizationMemberCap) error {
+	wrapMsg := "unable to set the organization member cap"
+
+	err := db.WithContext(ctx).
+		Omit("ResourceType").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_member_id"}, {Name: "resource_type_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"cap"}),
+		}).
+		Create(memberCap).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// DeleteOrganizationMemberCap removes the limit on the amount of a resource that an organization member may consume.
+// The number of caps removed is returned.
+func DeleteOrganizationMemberCap(ctx context.Context, db *gorm.DB, memberID, resourceTypeID string) (int64, error) {
+	wrapMsg := "unable to remove the organization member cap"
+
+	result := db.WithContext(ctx).
+		Where("organization_member_id = ?", memberID).
+		Where("resource_type_id = ?", resourceTypeID).
+		Delete(&model.OrganizationMemberCap{})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, wrapMsg)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetActiveOrganizationSubscriptionForDate retrieves the subscription that is active for an organization on the
+// specified date, using the same rules as GetActiveSubscriptionForDate. Unlike users, organizations aren't subscribed
+// to the default plan automatically, so a nil pointer is returned if the organization has no active subscription.
+func GetActiveOrganizationSubscriptionForDate(
+	ctx context.Context, db *gorm.DB, organizationID string, date time.Time,
+) (*model.Subscription, error) {
+	wrapMsg := fmt.Sprintf("unable to get the active subscription for organization '%s' at %s", organizationID, date)
+	var err error
+
+	var subscription model.Subscription
+	err = db.
+		WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Where(
+			db.Where("? BETWEEN effective_start_date AND effective_end_date", date).
+				Or("? > effective_start_date AND effective_end_date IS NULL", date),
+		).
+		Order("effective_start_date desc").
+		First(&subscription).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &subscription, nil
+}
+
+// GetOrganizationMemberUsage returns the amount of a resource that a user has consumed from an organization's pool.
+func GetOrganizationMemberUsage(
+	ctx context.Context, db *gorm.DB, subscriptionID, userID, resourceTypeID string,
+) (float64, error) {
+	wrapMsg := "unable to look up the organization member usage"
+	var err error
+
+	var usage float64
+	err = db.WithContext(ctx).
+		Model(&model.OrganizationMemberUsage{}).
+		Select("COALESCE(sum(usage), 0)").
+		Where("subscription_id = ?", subscriptionID).
+		Where("user_id = ?", userID).
+		Where("resource_type_id = ?", resourceTypeID).
+		Scan(&usage).
+		Error
+	if err != nil {
+		return 0, errors.Wrap(err, wrapMsg)
+	}
+
+	return usage, nil
+}
+
+// UpsertOrganizationMemberUsage records the amount of a resource that a user has consumed from an organization's pool.
+func UpsertOrganizationMemberUsage(ctx context.Context, db *gorm.DB, usage *model.OrganizationMemberUsage) error {
+	wrapMsg := "unable to update or insert the organization member usage"
+
+	err := db.WithContext(ctx).
+		Omit("User", "ResourceType").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "user_id"}, {Name: "resource_type_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"usage"}),
+		}).
+		Create(usage).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// ResetOrganizationMemberUsages sets the amount of a resource that each member has consumed from an organization's pool
+// to zero.
+func ResetOrganizationMemberUsages(ctx context.Context, db *gorm.DB, subscriptionID, resourceTypeID string) error {
+	wrapMsg := "unable to reset the organization member usages"
+
+	err := db.WithContext(ctx).
+		Model(&model.OrganizationMemberUsage{}).
+		Where("subscription_id = ?", subscriptionID).
+		Where("resource_type_id = ?", resourceTypeID).
+		UpdateColumn("usage", 0).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// ListOrganizationMemberUsages lists the amount of each resource that each member has consumed from an organization's
+// pool.
+func ListOrganizationMemberUsages(
+	ctx context.Context, db *gorm.DB, subscriptionID string,
+) ([]*model.OrganizationMemberUsage, error) {
+	wrapMsg := fmt.Sprintf("unable to list the member usages for subscription '%s'", subscriptionID)
+	var err error
+
+	usages := make([]*model.OrganizationMemberUsage, 0)
+	err = db.WithContext(ctx).
+		Joins("JOIN users ON organization_member_usages.user_id = users.id").
+		Joins("JOIN resource_types ON organization_member_usages.resource_type_id = resource_types.id").
+		Preload("User").
+		Preload("ResourceType").
+		Where("organization_member_usages.subscription_id = ?", subscriptionID).
+		Order("users.username asc, resource_types.name asc").
+		Find(&usages).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return usages, nil
+}
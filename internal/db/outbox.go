@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveOutboxEvent records a new event in the outbox. This function should be called in the same transaction as the
+// change that caused the event.
+func SaveOutboxEvent(ctx context.Context, db *gorm.DB, event *model.OutboxEvent) error {
+	wrapMsg := "unable to save the outbox event"
+
+	err := db.WithContext(ctx).Create(event).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// GetPendingOutboxEvents returns up to limit events that haven't been delivered yet, that have been attempted fewer
+// than maxAttempts times, and that aren't currently claimed by a dispatcher, oldest first. The rows are locked until
+// the end of the current transaction, and rows that have already been locked by another transaction are skipped, so
+// that multiple dispatchers may claim events concurrently.
+func GetPendingOutboxEvents(
+	ctx context.Context, db *gorm.DB, limit, maxAttempts int,
+) ([]*model.OutboxEvent, error) {
+	wrapMsg := "unable to look up pending outbox events"
+	var err error
+
+	var events []*model.OutboxEvent
+	err = db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("dispatched_at IS NULL").
+		Where("attempts < ?", maxAttempts).
+		Where("claimed_until IS NULL OR claimed_until < CURRENT_TIMESTAMP").
+		Order("created_at asc").
+		Limit(limit).
+		Find(&events).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return events, nil
+}
+
+// ClaimOutboxEvents claims the outbox events with the given identifiers for delivery until the given time. Claimed
+// events aren't returned by GetPendingOutboxEvents until the claim expires.
+func ClaimOutboxEvents(ctx context.Context, db *gorm.DB, eventIDs []string, claimedUntil time.Time) error {
+	wrapMsg := "unable to claim outbox events"
+
+	if len(eventIDs) == 0 {
+		return nil
+	}
+
+	err := db.WithContext(ctx).
+		Model(&model.OutboxEvent{}).
+		Where("id IN ?", eventIDs).
+		Update("claimed_until", claimedUntil).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// MarkOutboxEventDispatched records the successful delivery of an outbox event.
+func MarkOutboxEventDispatched(ctx context.Context, db *gorm.DB, eventID string) error {
+	wrapMsg := fmt.Sprintf("unable to mark outbox event '%s' as dispatched", eventID)
+
+	err := db.WithContext(ctx).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", eventID).
+		Update("dispatched_at", gorm.Expr("CURRENT_TIMESTAMP")).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// MarkOutboxEventFailed records a failed attempt to deliver an outbox event. The dispatcher's claim on the event is
+// released so that delivery can be retried on the next run.
+func MarkOutboxEventFailed(ctx context.Context, db *gorm.DB, eventID string, cause error) error {
+	wrapMsg := fmt.Sprintf("unable to record a failed delivery attempt for outbox event '%s'", eventID)
+
+	err := db.WithContext(ctx).
+		Model(&model.OutboxEvent{}).
+		Where("id = ?", eventID).
+		Updates(map[string]interface{}{
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    cause.Error(),
+			"claimed_until": nil,
+		}).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cyverse/qms/internal/model"
+	"gorm.io/gorm"
+)
+
+// OverageListingParams represents the parameters that can be used to customize an overage listing.
+type OverageListingParams struct {
+	Offset               int
+	Limit                int
+	SortField            string
+	SortDir              string
+	Search               string
+	ResourceTypeName     string
+	MinOveragePercentage float64
+}
+
+// ListOverages lists resource types in active subscriptions for which the usage is at or above the quota. The
+// comparison is done entirely in the database so that the full subscription details don't have to be loaded. Resource
+// types with no usage are never listed, even if the quota is zero.
+func ListOverages(
+	ctx context.Context, db *gorm.DB, params *OverageListingParams,
+) ([]*model.SubscriptionOverage, int64, error) {
+	var overages []*model.SubscriptionOverage
+	var count int64
+
+	// Determine the offset and limit to use.
+	var offset int = 0
+	if params != nil && params.Offset >= 0 {
+		offset = params.Offset
+	}
+	var limit int = 50
+	if params != nil && params.Limit >= 0 {
+		limit = params.Limit
+	}
+
+	// Determine the sort field and sort order to use.
+	sortField := "users.username"
+	if params != nil && params.SortField != "" {
+		sortField = params.SortField
+	}
+	order := "asc"
+	if params != nil && params.SortDir != "" {
+		order = params.SortDir
+	}
+	orderBy := fmt.Sprintf("%s %s, users.username asc, resource_types.name asc", sortField, order)
+
+	// Build the base query.
+	baseQuery := db.WithContext(ctx).
+		Table("subscriptions").
+		Joins("JOIN users ON subscriptions.user_id = users.id").
+		Joins("JOIN plans ON subscriptions.plan_id = plans.id").
+		Joins("JOIN quotas ON subscriptions.id = quotas.subscription_id").
+		Joins(
+			"JOIN usages ON subscriptions.id = usages.subscription_id " +
+				"AND quotas.resource_type_id = usages.resource_type_id",
+		).
+		Joins("JOIN resource_types ON quotas.resource_type_id = resource_types.id").
+		Where(
+			db.Where("CURRENT_TIMESTAMP BETWEEN subscriptions.effective_start_date AND subscriptions.effective_end_date").
+				Or("CURRENT_TIMESTAMP > subscriptions.effective_start_date AND subscriptions.effective_end_date IS NULL"),
+		).
+		Where("usages.usage >= quotas.quota").
+		Where("usages.usage > 0")
+
+	// Add the optional filters if we're supposed to.
+	if params != nil && params.Search != "" {
+		search := strings.ReplaceAll(params.Search, "%", "\\%")
+		search = strings.ReplaceAll(search, "_", "\\_")
+		baseQuery = baseQuery.Where("users.username LIKE ?", "%"+search+"%")
+	}
+	if params != nil && params.ResourceTypeName != "" {
+		baseQuery = baseQuery.Where("resource_types.name = ?", params.ResourceTypeName)
+	}
+	if params != nil && params.MinOveragePercentage > 0 {
+		baseQuery = baseQuery.Where("(usages.usage - quotas.quota) * 100 >= ? * quotas.quota", params.MinOveragePercentage)
+	}
+
+	// Count the number of items in the result set.
+	err := baseQuery.Count(&count).Error
+
+	// Look up the result set.
+	if err == nil {
+		err = baseQuery.
+			Select(
+				"subscriptions.id AS subscription_id",
+				"users.username AS username",
+				"plans.name AS plan_name",
+				"resource_types.name AS resource_type_name",
+				"resource_types.unit AS resource_type_unit",
+				"quotas.quota AS quota",
+				"usages.usage AS usage",
+				"usages.usage - quotas.quota AS overage",
+				"(usages.usage - quotas.quota) * 100 / NULLIF(quotas.quota, 0) AS overage_percentage",
+			).
+			Offset(offset).
+			Limit(limit).
+			Order(orderBy).
+			Scan(&overages).Error
+	}
+
+	return overages, count, err
+}
@@ -151,15 +151,20 @@ func GetActivePlanQuotaDefaults(ctx context.Context, db *gorm.DB, planID string)
 	return planQuotaDefaults, nil
 }
 
-// ListPlans lists all of the plans that are currently available.
-func ListPlans(ctx context.Context, db *gorm.DB) ([]*model.Plan, error) {
+// ListPlans lists the plans in the database. Archived plans are only included if includeArchived is true.
+func ListPlans(ctx context.Context, db *gorm.DB, includeArchived bool) ([]*model.Plan, error) {
 	wrapMsg := "unable to list plans"
 	var err error
 
+	// Exclude archived plans unless we're supposed to include them.
+	query := db.WithContext(ctx)
+	if !includeArchived {
+		query = query.Where("NOT archived")
+	}
+
 	// List the plans.
 	var plans []*model.Plan
-	err = db.
-		WithContext(ctx).
+	err = query.
 		Preload("PlanQuotaDefaults", func(db *gorm.DB) *gorm.DB {
 			return db.
 				Joins("INNER JOIN resource_types ON plan_quota_defaults.resource_type_id = resource_types.id").
@@ -177,6 +182,43 @@ func ListPlans(ctx context.Context, db *gorm.DB) ([]*model.Plan, error) {
 	return plans, nil
 }
 
+// UpdatePlan updates the name and description of an existing plan.
+func UpdatePlan(ctx context.Context, db *gorm.DB, plan *model.Plan) error {
+	wrapMsg := "unable to update plan"
+
+	// Make sure that the incoming plan has an identifier associated with it.
+	if plan.ID == nil || *plan.ID == "" {
+		return fmt.Errorf("%s: no plan ID specified", wrapMsg)
+	}
+
+	err := db.WithContext(ctx).
+		Model(plan).
+		Select("Name", "Description").
+		Updates(plan).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// SetPlanArchived archives or restores the plan with the given identifier.
+func SetPlanArchived(ctx context.Context, db *gorm.DB, planID string, archived bool) error {
+	wrapMsg := fmt.Sprintf("unable to update the archived flag for plan ID '%s'", planID)
+
+	err := db.WithContext(ctx).
+		Model(&model.Plan{}).
+		Where("id = ?", planID).
+		Update("archived", archived).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
 func GetDefaultQuotaForPlan(ctx context.Context, db *gorm.DB, planID string) ([]model.PlanQuotaDefault, error) {
 	wrapMsg := "unable to look up plan name"
 	var err error
@@ -191,9 +233,9 @@ func GetDefaultQuotaForPlan(ctx context.Context, db *gorm.DB, planID string) ([]
 	return planQuotaDefaults, nil
 }
 
-// GetPlansByName builds a map from plan name to plan details.
+// GetPlansByName builds a map from plan name to plan details. Archived plans are included in the map.
 func GetPlansByName(ctx context.Context, db *gorm.DB) (map[string]*model.Plan, error) {
-	plans, err := ListPlans(ctx, db)
+	plans, err := ListPlans(ctx, db, true)
 	if err != nil {
 		return nil, err
 	}
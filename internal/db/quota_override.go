@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveQuotaOverride records a new quota override in the database.
+func SaveQuotaOverride(ctx context.Context, db *gorm.DB, override *model.QuotaOverride) error {
+	wrapMsg := "unable to save the quota override"
+
+	err := db.WithContext(ctx).Omit("ResourceType").Create(override).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// GetQuotaOverride looks up the quota override with the given identifier. A nil pointer is returned if the quota
+// override doesn't exist. If forUpdate is true then the quota override row is locked until the end of the current
+// transaction.
+func GetQuotaOverride(
+	ctx context.Context, db *gorm.DB, overrideID string, forUpdate bool,
+) (*model.QuotaOverride, error) {
+	wrapMsg := fmt.Sprintf("unable to look up quota override '%s'", overrideID)
+	var err error
+
+	query := db.WithContext(ctx).Preload("ResourceType")
+	if forUpdate {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var override model.QuotaOverride
+	err = query.Where("id = ?", overrideID).First(&override).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &override, nil
+}
+
+// ListQuotaOverridesForUser lists the quota overrides in all of a user's subscriptions, most recent first. Retired
+// quota overrides are only included if includeRetired is true.
+func ListQuotaOverridesForUser(
+	ctx context.Context, db *gorm.DB, username string, includeRetired bool,
+) ([]*model.QuotaOverride, error) {
+	wrapMsg := fmt.Sprintf("unable to list the quota overrides for user '%s'", username)
+	var err error
+
+	query := db.WithContext(ctx).
+		Preload("ResourceType").
+		Joins("JOIN subscriptions ON quota_overrides.subscription_id = subscriptions.id").
+		Joins("JOIN users ON subscriptions.user_id = users.id").
+		Where("users.username = ?", username)
+	if !includeRetired {
+		query = query.Where("quota_overrides.status != ?", model.QuotaOverrideStatusRetired)
+	}
+
+	overrides := make([]*model.QuotaOverride, 0)
+	err = query.Order("quota_overrides.effective_start_date desc").Find(&overrides).Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return overrides, nil
+}
+
+// ListUnretiredQuotaOverridesForUserID lists the quota overrides in a user's subscriptions that haven't been retired
+// yet, oldest first, locking them until the end of the current transaction.
+func ListUnretiredQuotaOverridesForUserID(
+	ctx context.Context, db *gorm.DB, userID string,
+) ([]*model.QuotaOverride, error) {
+	wrapMsg := fmt.Sprintf("unable to list the unretired quota overrides for user ID '%s'", userID)
+	var err error
+
+	overrides := make([]*model.QuotaOverride, 0)
+	err = db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "quota_overrides"}}).
+		Joins("JOIN subscriptions ON quota_overrides.subscription_id = subscriptions.id").
+		Where("subscriptions.user_id = ?", userID).
+		Where("quota_overrides.status != ?", model.QuotaOverrideStatusRetired).
+		Order("quota_overrides.effective_start_date asc").
+		Find(&overrides).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return overrides, nil
+}
+
+// ListDueQuotaOverrides lists quota overrides that are due to be activated or retired as of the given time, locking
+// them until the end of the current transaction. Quota overrides that are already locked by another transaction are
+// skipped.
+func ListDueQuotaOverrides(ctx context.Context, db *gorm.DB, now time.Time, limit int) ([]*model.QuotaOverride, error) {
+	wrapMsg := "unable to list the quota overrides that are due to be activated or retired"
+	var err error
+
+	var overrides []*model.QuotaOverride
+	err = db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status != ?", model.QuotaOverrideStatusRetired).
+		Where(
+			db.Where("effective_end_date <= ?", now).
+				Or("status = ? AND effective_start_date <= ?", model.QuotaOverrideStatusPending, now),
+		).
+		Order("effective_start_date asc").
+		Limit(limit).
+		Find(&overrides).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return overrides, nil
+}
+
+// UpdateQuotaOverrideStatus records the status of a quota override, along with the time it was retired if applicable.
+func UpdateQuotaOverrideStatus(ctx context.Context, db *gorm.DB, override *model.QuotaOverride) error {
+	wrapMsg := fmt.Sprintf("unable to update the status of quota override '%s'", *override.ID)
+
+	err := db.WithContext(ctx).
+		Model(override).
+		Select("Status", "RetiredAt").
+		Updates(override).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// UpdateQuotaOverrideEndDate records a new effective end date for a quota override.
+func UpdateQuotaOverrideEndDate(ctx context.Context, db *gorm.DB, override *model.QuotaOverride) error {
+	wrapMsg := fmt.Sprintf("unable to update the end date of quota override '%s'", *override.ID)
+
+	err := db.WithContext(ctx).
+		Model(override).
+		Select("EffectiveEndDate").
+		Updates(override).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// GetActiveQuotaOverrideTotal returns the total amount by which active quota overrides have raised the quota for a
+// resource type in a subscription.
+func GetActiveQuotaOverrideTotal(
+	ctx context.Context, db *gorm.DB, subscriptionID, resourceTypeID string,
+) (float64, error) {
+	wrapMsg := "unable to determine the total amount of the active quota overrides"
+	var err error
+
+	var total float64
+	err = db.WithContext(ctx).
+		Model(&model.QuotaOverride{}).
+		Select("COALESCE(sum(amount), 0)").
+		Where("subscription_id = ?", subscriptionID).
+		Where("resource_type_id = ?", resourceTypeID).
+		Where("status = ?", model.QuotaOverrideStatusActive).
+		Scan(&total).
+		Error
+	if err != nil {
+		return 0, errors.Wrap(err, wrapMsg)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveReservation records a new reservation in the database.
+func SaveReservation(ctx context.Context, db *gorm.DB, reservation *model.Reservation) error {
+	wrapMsg := "unable to save the reservation"
+
+	err := db.WithContext(ctx).Omit("ResourceType").Create(reservation).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// GetReservation looks up the reservation with the given identifier. A nil pointer is returned if the reservation
+// doesn't exist. If forUpdate is true then the reservation row is locked until the end of the current transaction.
+func GetReservation(
+	ctx context.Context, db *gorm.DB, reservationID string, forUpdate bool,
+) (*model.Reservation, error) {
+	wrapMsg := fmt.Sprintf("unable to look up reservation '%s'", reservationID)
+	var err error
+
+	query := db.WithContext(ctx).Preload("ResourceType")
+	if forUpdate {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var reservation model.Reservation
+	err = query.Where("id = ?", reservationID).First(&reservation).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &reservation, nil
+}
+
+// LockReservations obtains a transaction-level lock that serializes the placement of holds and changes to the usage for
+// a resource type within a subscription. The lock is released automatically when the current transaction ends.
+func LockReservations(ctx context.Context, db *gorm.DB, subscriptionID, resourceTypeID string) error {
+	wrapMsg := "unable to lock reservations for the subscription"
+
+	err := db.WithContext(ctx).
+		Exec("SELECT pg_advisory_xact_lock(hashtext(?))", subscriptionID+":"+resourceTypeID).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// GetOutstandingReservationTotal returns the total amount of a resource type that is currently held by reservations
+// that have been neither committed, released, nor expired.
+func GetOutstandingReservationTotal(
+	ctx context.Context, db *gorm.DB, subscriptionID, resourceTypeID string,
+) (float64, error) {
+	wrapMsg := "unable to determine the total amount held by outstanding reservations"
+	var err error
+
+	var total float64
+	err = db.WithContext(ctx).
+		Model(&model.Reservation{}).
+		Select("COALESCE(sum(amount), 0)").
+		Where("subscription_id = ?", subscriptionID).
+		Where("resource_type_id = ?", resourceTypeID).
+		Where("status = ?", model.ReservationStatusHeld).
+		Where("expires_at > CURRENT_TIMESTAMP").
+		Scan(&total).
+		Error
+	if err != nil {
+		return 0, errors.Wrap(err, wrapMsg)
+	}
+
+	return total, nil
+}
+
+// UpdateReservationStatus updates the status and committed amount of an existing reservation.
+func UpdateReservationStatus(ctx context.Context, db *gorm.DB, reservation *model.Reservation) error {
+	wrapMsg := fmt.Sprintf("unable to update the status of reservation '%s'", *reservation.ID)
+
+	err := db.WithContext(ctx).
+		Model(reservation).
+		Select("Status", "CommittedAmount").
+		Updates(reservation).
+		Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// ExpireReservations marks all holds whose expiration time has passed as expired. The number of reservations that were
+// marked as expired is returned.
+func ExpireReservations(ctx context.Context, db *gorm.DB) (int64, error) {
+	wrapMsg := "unable to expire reservations"
+
+	result := db.WithContext(ctx).
+		Model(&model.Reservation{}).
+		Where("status = ?", model.ReservationStatusHeld).
+		Where("expires_at <= CURRENT_TIMESTAMP").
+		Update("status", model.ReservationStatusExpired)
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, wrapMsg)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetOutstandingMemberReservationTotal returns the total amount of a resource type that is currently held by a user's
+// outstanding reservations against a subscription. This is used to determine how much of an organization's pool is
+// held by a single member.
+func GetOutstandingMemberReservationTotal(
+	ctx context.Context, db *gorm.DB, subscriptionID, userID, resourceTypeID string,
+) (float64, error) {
+	wrapMsg := "unable to determine the total amount held by the member's outstanding reservations"
+	var err error
+
+	var total float64
+	err = db.WithContext(ctx).
+		Model(&model.Reservation{}).
+		Select("COALESCE(sum(amount), 0)").
+		Where("subscription_id = ?", subscriptionID).
+		Where("user_id = ?", userID).
+		Where("resource_type_id = ?", resourceTypeID).
+		Where("status = ?", model.ReservationStatusHeld).
+		Where("expires_at > CURRENT_TIMESTAMP").
+		Scan(&total).
+		Error
+	if err != nil {
+		return 0, errors.Wrap(err, wrapMsg)
+	}
+
+	return total, nil
+}
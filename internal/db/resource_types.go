@@ -85,7 +85,7 @@ func SaveResourceType(ctx context.Context, db *gorm.DB, resourceType model.Resou
 
 	// Save the resource type.
 	err = db.
-		Select("ID", "Name", "Unit", "Consumable").
+		Select("ID", "Name", "Unit", "Consumable", "WarningPercentage", "GracePercentage").
 		Clauses(clause.OnConflict{DoNothing: true}).
 		Create(&resourceType).
 		Error
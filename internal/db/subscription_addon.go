@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// SaveSubscriptionAddon records the application of an addon to a subscription.
+func SaveSubscriptionAddon(ctx context.Context, db *gorm.DB, subscriptionAddon *model.SubscriptionAddon) error {
+	wrapMsg := "unable to save the subscription addon"
+
+	err := db.WithContext(ctx).Omit("Addon", "AddonRate").Create(subscriptionAddon).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// GetSubscriptionAddon looks up an addon that has been applied to the subscription with the given identifier. A nil
+// pointer is returned if the subscription addon doesn't exist.
+func GetSubscriptionAddon(
+	ctx context.Context, db *gorm.DB, subscriptionID, subscriptionAddonID string,
+) (*model.SubscriptionAddon, error) {
+	wrapMsg := fmt.Sprintf("unable to look up subscription addon '%s'", subscriptionAddonID)
+	var err error
+
+	var subscriptionAddon model.SubscriptionAddon
+	err = db.WithContext(ctx).
+		Preload("Addon").
+		Preload("Addon.ResourceType").
+		Preload("AddonRate").
+		Where("id = ? AND subscription_id = ?", subscriptionAddonID, subscriptionID).
+		First(&subscriptionAddon).
+		Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &subscriptionAddon, nil
+}
+
+// DeleteSubscriptionAddon removes an addon from a subscription.
+func DeleteSubscriptionAddon(ctx context.Context, db *gorm.DB, subscriptionAddonID string) error {
+	wrapMsg := fmt.Sprintf("unable to delete subscription addon '%s'", subscriptionAddonID)
+
+	err := db.WithContext(ctx).Delete(&model.SubscriptionAddon{ID: &subscriptionAddonID}).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
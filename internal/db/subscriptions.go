@@ -38,9 +38,41 @@ func QuotasFromPlan(plan *model.Plan, periods int32) []model.Quota {
 	return result
 }
 
-// SubscribeUserToPlan subscribes the given user to the given plan.
+// AllotmentsFromPlan generates the per-period allotments of consumable resources from the quota defaults in a plan.
+func AllotmentsFromPlan(plan *model.Plan) []model.SubscriptionAllotment {
+	result := make([]model.SubscriptionAllotment, 0)
+	for _, quotaDefault := range plan.GetDefaultQuotaValues() {
+		if quotaDefault.ResourceType.Consumable {
+			result = append(result, model.SubscriptionAllotment{
+				ResourceTypeID: quotaDefault.ResourceTypeID,
+				Amount:         quotaDefault.QuotaValue,
+			})
+		}
+	}
+	return result
+}
+
+// SubscribeUserToPlan subscribes the given user to the given plan. Consumable quotas are multiplied by the number of
+// periods in the subscription unless the subscription is in allotment mode, in which case the quotas cover a single
+// period and are reset at the beginning of each subsequent period.
 func SubscribeUserToPlan(
 	ctx context.Context, db *gorm.DB, user *model.User, plan *model.Plan, opts *model.SubscriptionOptions,
+) (*model.Subscription, error) {
+	return subscribeToPlan(ctx, db, &model.Subscription{UserID: user.ID}, plan, opts)
+}
+
+// SubscribeOrganizationToPlan subscribes the given organization to the given plan. The quotas in the subscription are
+// shared by all of the organization's members.
+func SubscribeOrganizationToPlan(
+	ctx context.Context, db *gorm.DB, organization *model.Organization, plan *model.Plan, opts *model.SubscriptionOptions,
+) (*model.Subscription, error) {
+	return subscribeToPlan(ctx, db, &model.Subscription{OrganizationID: organization.ID}, plan, opts)
+}
+
+// subscribeToPlan subscribes the owner of the given subscription to the given plan. Only the owner needs to be set in
+// the subscription that is passed to this function; the rest of the subscription is populated from the plan.
+func subscribeToPlan(
+	ctx context.Context, db *gorm.DB, owner *model.Subscription, plan *model.Plan, opts *model.SubscriptionOptions,
 ) (*model.Subscription, error) {
 	wrapMsg := "unable to add user plan"
 	var err error
@@ -57,12 +89,23 @@ func SubscribeUserToPlan(
 	subscription := model.Subscription{
 		EffectiveStartDate: &effectiveStartDate,
 		EffectiveEndDate:   &effectiveEndDate,
-		UserID:             user.ID,
+		UserID:             owner.UserID,
+		OrganizationID:     owner.OrganizationID,
 		PlanID:             plan.ID,
 		Quotas:             QuotasFromPlan(plan, opts.GetPeriods()),
 		Paid:               opts.IsPaid(),
 		PlanRateID:         planRate.ID,
 	}
+
+	// Subscriptions in allotment mode receive one period's worth of each consumable resource at a time.
+	if opts.IsAllotmentMode() {
+		periodEnd := subscription.AllotmentPeriodEnd(effectiveStartDate)
+		subscription.AllotmentMode = true
+		subscription.CurrentPeriodStart = &effectiveStartDate
+		subscription.CurrentPeriodEnd = &periodEnd
+		subscription.Quotas = QuotasFromPlan(plan, 1)
+		subscription.Allotments = AllotmentsFromPlan(plan)
+	}
 	err = db.WithContext(ctx).Create(&subscription).Error
 	if err != nil {
 		return nil, errors.Wrap(err, wrapMsg)
@@ -114,7 +157,7 @@ func GetActiveSubscriptionForDate(
 		Where("users.username=?", username).
 		Where(
 			db.Where("? BETWEEN subscriptions.effective_start_date AND subscriptions.effective_end_date", date).
-				Or("? > subscriptions.effective_start_date AND subscriptions.effective_end_date IS NULL"),
+				Or("? > subscriptions.effective_start_date AND subscriptions.effective_end_date IS NULL", date),
 		).
 		Order("subscriptions.effective_start_date desc").
 		First(&subscription).Error
@@ -193,6 +236,7 @@ func GetSubscriptionDetails(ctx context.Context, db *gorm.DB, subscriptionID str
 
 	err := db.WithContext(ctx).
 		Preload("User").
+		Preload("Organization").
 		Preload("Plan").
 		Preload("Plan.PlanQuotaDefaults", func(db *gorm.DB) *gorm.DB {
 			return db.
@@ -208,11 +252,23 @@ func GetSubscriptionDetails(ctx context.Context, db *gorm.DB, subscriptionID str
 		Preload("Usages").
 		Preload("Usages.ResourceType").
 		Preload("PlanRate").
+		Preload("SubscriptionAddons").
+		Preload("SubscriptionAddons.Addon").
+		Preload("SubscriptionAddons.Addon.ResourceType").
+		Preload("SubscriptionAddons.AddonRate").
+		Preload("Allotments").
+		Preload("Allotments.ResourceType").
 		Where("id = ?", subscriptionID).
 		First(&subscription).
 		Error
+	if err != nil {
+		return subscription, err
+	}
 
-	return subscription, err
+	// Compute the quota limits and states.
+	subscription.UpdateQuotaLimits()
+
+	return subscription, nil
 }
 
 // SubscriptionListingParams represents the parameters that can be used to customize a user plan listing.
@@ -271,6 +327,12 @@ func ListSubscriptions(
 		Preload("Usages").
 		Preload("Usages.ResourceType").
 		Preload("PlanRate").
+		Preload("SubscriptionAddons").
+		Preload("SubscriptionAddons.Addon").
+		Preload("SubscriptionAddons.Addon.ResourceType").
+		Preload("SubscriptionAddons.AddonRate").
+		Preload("Allotments").
+		Preload("Allotments.ResourceType").
 		Where(
 			db.Where("CURRENT_TIMESTAMP BETWEEN subscriptions.effective_start_date AND subscriptions.effective_end_date").
 				Or("CURRENT_TIMESTAMP > subscriptions.effective_start_date AND subscriptions.effective_end_date IS NULL"),
@@ -297,6 +359,11 @@ func ListSubscriptions(
 			Find(&subscriptions).Error
 	}
 
+	// Compute the quota limits and states.
+	for _, subscription := range subscriptions {
+		subscription.UpdateQuotaLimits()
+	}
+
 	return subscriptions, count, err
 }
 
@@ -325,6 +392,12 @@ func ListSubscriptionsForUser(
 		Preload("Usages").
 		Preload("Usages.ResourceType").
 		Preload("PlanRate").
+		Preload("SubscriptionAddons").
+		Preload("SubscriptionAddons.Addon").
+		Preload("SubscriptionAddons.Addon.ResourceType").
+		Preload("SubscriptionAddons.AddonRate").
+		Preload("Allotments").
+		Preload("Allotments.ResourceType").
 		Where("users.username = ?", username)
 
 	// Add the where clause for the cutoff if we're supposed to.
@@ -346,6 +419,11 @@ func ListSubscriptionsForUser(
 			Find(&subscriptions).Error
 	}
 
+	// Compute the quota limits and states.
+	for _, subscription := range subscriptions {
+		subscription.UpdateQuotaLimits()
+	}
+
 	return subscriptions, count, err
 }
 
@@ -367,6 +445,64 @@ func GetActiveSubscriptionDetails(ctx context.Context, db *gorm.DB, username str
 	return GetSubscriptionDetails(ctx, db, *subscription.ID)
 }
 
+// GetActiveSubscriptionUsageDetails retrieves the user plan information that is currently active for the user, loading
+// only the details that are required to apply a usage update: the user, quotas, and usages. If no active user plans
+// exist for the user then a new one for the basic plan is created. This function is a lighter weight alternative to
+// GetActiveSubscriptionDetails for callers that process large numbers of usage updates.
+func GetActiveSubscriptionUsageDetails(ctx context.Context, db *gorm.DB, username string) (*model.Subscription, error) {
+	wrapMsg := "unable to load the usage details for the active user plan"
+	var err error
+
+	// Get the current user plan.
+	subscription, err := GetActiveSubscription(ctx, db, username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the details required to apply usage updates.
+	err = loadSubscriptionUsageDetails(ctx, db, subscription)
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return subscription, nil
+}
+
+// GetActiveSubscriptionUsageDetailsForDate retrieves the subscription that was active for the user as of the given
+// date, loading only the details that are required to apply a usage update: the user, quotas, and usages. If there are
+// no active subscriptions as of the given date then a null pointer will be returned instead.
+func GetActiveSubscriptionUsageDetailsForDate(
+	ctx context.Context, db *gorm.DB, username string, date time.Time,
+) (*model.Subscription, error) {
+	wrapMsg := fmt.Sprintf("unable to load the usage details for the user plan active at %s", date)
+	var err error
+
+	// Get the subscription that was active as of the given date if there is one.
+	subscription, err := GetActiveSubscriptionForDate(ctx, db, username, date)
+	if subscription == nil || err != nil {
+		return subscription, err
+	}
+
+	// Load the details required to apply usage updates.
+	err = loadSubscriptionUsageDetails(ctx, db, subscription)
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return subscription, nil
+}
+
+// loadSubscriptionUsageDetails loads the subscription details that are required to apply usage updates.
+func loadSubscriptionUsageDetails(ctx context.Context, db *gorm.DB, subscription *model.Subscription) error {
+	return db.WithContext(ctx).
+		Preload("User").
+		Preload("Quotas").
+		Preload("Usages").
+		Where("id = ?", *subscription.ID).
+		First(subscription).
+		Error
+}
+
 // GetActiveSubscriptionDetailsForDate retrieves the active subscription for the user as of the given date. The active
 // subscription is determined by comparing the effective start and end dates for the subscription to the given date. For
 // a subscription to be considered active as of the given date, the effective start date must be prior to the given date
@@ -391,15 +527,79 @@ func GetActiveSubscriptionDetailsForDate(
 	return GetSubscriptionDetails(ctx, db, *subscription.ID)
 }
 
+// ListSubscriptionStartDates lists the effective start dates of a user's subscriptions that begin before the given
+// time, in ascending order.
+func ListSubscriptionStartDates(
+	ctx context.Context, db *gorm.DB, username string, until time.Time,
+) ([]time.Time, error) {
+	wrapMsg := fmt.Sprintf("unable to list the subscription start dates for user '%s'", username)
+	var err error
+
+	var startDates []time.Time
+	err = db.WithContext(ctx).
+		Model(&model.Subscription{}).
+		Joins("JOIN users ON subscriptions.user_id = users.id").
+		Where("users.username = ?", username).
+		Where("subscriptions.effective_start_date < ?", until).
+		Order("subscriptions.effective_start_date asc").
+		Pluck("subscriptions.effective_start_date", &startDates).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return startDates, nil
+}
+
+// ListSubscriptionUsagesForUser lists all of a user's subscriptions, along with their recorded usage values, in order
+// of their effective start dates.
+func ListSubscriptionUsagesForUser(
+	ctx context.Context, db *gorm.DB, username string,
+) ([]*model.Subscription, error) {
+	wrapMsg := fmt.Sprintf("unable to list the subscription usages for user '%s'", username)
+	var err error
+
+	var subscriptions []*model.Subscription
+	err = db.WithContext(ctx).
+		Joins("JOIN users ON subscriptions.user_id = users.id").
+		Preload("Usages").
+		Preload("Usages.ResourceType").
+		Where("users.username = ?", username).
+		Order("subscriptions.effective_start_date asc").
+		Find(&subscriptions).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return subscriptions, nil
+}
+
 // DeactivateSubscriptions marks subscriptions for a user as expired. This operation is used when a user subscribes to a
 // new plan.
 func DeactivateSubscriptions(ctx context.Context, db *gorm.DB, userID string, startDate, endDate time.Time) error {
-	wrapMsg := "unable to deactivate active plans for user"
+	return deactivateSubscriptions(ctx, db, "user_id", userID, startDate, endDate)
+}
+
+// DeactivateOrganizationSubscriptions marks subscriptions for an organization as expired. This operation is used when
+// an organization subscribes to a new plan.
+func DeactivateOrganizationSubscriptions(
+	ctx context.Context, db *gorm.DB, organizationID string, startDate, endDate time.Time,
+) error {
+	return deactivateSubscriptions(ctx, db, "organization_id", organizationID, startDate, endDate)
+}
+
+// deactivateSubscriptions marks the subscriptions whose owner is identified by the given column and ID as expired.
+func deactivateSubscriptions(
+	ctx context.Context, db *gorm.DB, ownerColumn, ownerID string, startDate, endDate time.Time,
+) error {
+	wrapMsg := "unable to deactivate active plans"
+	ownerCondition := fmt.Sprintf("%s = ?", ownerColumn)
 
 	// Subscriptions that should be marked as inactive as of the start date.
 	err := db.WithContext(ctx).
 		Model(&model.Subscription{}).
-		Where("user_id = ?", userID).
+		Where(ownerCondition, ownerID).
 		Where("effective_start_date <= ?", startDate).
 		Where("effective_end_date > ?", startDate).
 		UpdateColumn("effective_end_date", startDate).
@@ -411,7 +611,7 @@ func DeactivateSubscriptions(ctx context.Context, db *gorm.DB, userID string, st
 	// Subscriptions that should become effective as of the end date.
 	err = db.WithContext(ctx).
 		Model(&model.Subscription{}).
-		Where("user_id = ?", userID).
+		Where(ownerCondition, ownerID).
 		Where("effective_start_date >= ?", startDate).
 		Where("effective_end_date > ?", endDate).
 		UpdateColumn("effective_start_date", endDate).
@@ -423,7 +623,7 @@ func DeactivateSubscriptions(ctx context.Context, db *gorm.DB, userID string, st
 	// Subscriptions that should never become effective.
 	err = db.WithContext(ctx).
 		Model(&model.Subscription{}).
-		Where("user_id = ?", userID).
+		Where(ownerCondition, ownerID).
 		Where("effective_start_date >= ?", startDate).
 		Where("effective_end_date <= ?", endDate).
 		UpdateColumn("effective_end_date", gorm.Expr("effective_start_date")).
@@ -0,0 +1,329 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetUpdateOperation looks up the update operation with the given name. A nil pointer is returned if the update
+// operation doesn't exist.
+func GetUpdateOperation(ctx context.Context, db *gorm.DB, name string) (*model.UpdateOperation, error) {
+	wrapMsg := fmt.Sprintf("unable to look up update operation '%s'", name)
+	var err error
+
+	var updateOperation model.UpdateOperation
+	err = db.WithContext(ctx).Where("name = ?", name).First(&updateOperation).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &updateOperation, nil
+}
+
+// ListUpdateOperations lists all of the update operations that are currently defined.
+func ListUpdateOperations(ctx context.Context, db *gorm.DB) ([]*model.UpdateOperation, error) {
+	wrapMsg := "unable to list update operations"
+	var err error
+
+	var updateOperations []*model.UpdateOperation
+	err = db.WithContext(ctx).Order("name asc").Find(&updateOperations).Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return updateOperations, nil
+}
+
+// SaveUpdate records an update to a quota or usage value in the database.
+func SaveUpdate(ctx context.Context, db *gorm.DB, update *model.Update) error {
+	wrapMsg := "unable to record the update"
+
+	err := db.WithContext(ctx).Create(update).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// LockIdempotencyKey obtains a transaction-level lock that serializes the processing of requests with the same
+// idempotency key. The lock is released automatically when the current transaction ends.
+func LockIdempotencyKey(ctx context.Context, db *gorm.DB, idempotencyKey string) error {
+	wrapMsg := "unable to lock the idempotency key"
+
+	err := db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", idempotencyKey).Error
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// GetUpdateByIdempotencyKey looks up the update that was recorded with the given idempotency key. A nil pointer is
+// returned if no update was recorded with the idempotency key.
+func GetUpdateByIdempotencyKey(ctx context.Context, db *gorm.DB, idempotencyKey string) (*model.Update, error) {
+	wrapMsg := "unable to look up the update for the idempotency key"
+	var err error
+
+	var update model.Update
+	err = db.WithContext(ctx).Where("idempotency_key = ?", idempotencyKey).First(&update).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &update, nil
+}
+
+// GetUpdate looks up the update with the given identifier, along with its update operation and user. A nil pointer is
+// returned if the update doesn't exist. If forUpdate is true then the update row is locked until the end of the current
+// transaction.
+func GetUpdate(ctx context.Context, db *gorm.DB, updateID string, forUpdate bool) (*model.Update, error) {
+	wrapMsg := fmt.Sprintf("unable to look up update '%s'", updateID)
+	var err error
+
+	query := db.WithContext(ctx).Preload("UpdateOperation").Preload("User")
+	if forUpdate {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	var update model.Update
+	err = query.Where("id = ?", updateID).First(&update).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &update, nil
+}
+
+// CheckUpdateReversal determines whether or not the update with the given identifier has already been reversed.
+func CheckUpdateReversal(ctx context.Context, db *gorm.DB, updateID string) (bool, error) {
+	wrapMsg := fmt.Sprintf("unable to determine whether update '%s' has been reversed", updateID)
+	var err error
+
+	var reversed bool
+	err = db.WithContext(ctx).
+		Model(&model.Update{}).
+		Select("count(*) > 0").
+		Joins("JOIN update_operations ON updates.update_operation_id = update_operations.id").
+		Where("updates.original_update_id = ?", updateID).
+		Where("update_operations.name = ?", model.UpdateOperationReverse).
+		Find(&reversed).
+		Error
+	if err != nil {
+		return false, errors.Wrap(err, wrapMsg)
+	}
+
+	return reversed, nil
+}
+
+// ListUsageUpdatesForUser lists the usage updates recorded for a user, along with their update operations, in order of
+// their effective dates. If the since argument is not nil then only updates that take effect at or after that time are
+// listed. If the until argument is not nil then only updates that take effect before that time are listed. If the
+// resource type ID is not nil then only updates for that resource type are listed. Usage updates charged against an
+// organization's pool aren't included, because they don't apply to the user's own subscriptions.
+func ListUsageUpdatesForUser(
+	ctx context.Context, db *gorm.DB, username string, resourceTypeID *string, since, until *time.Time,
+) ([]model.Update, error) {
+	wrapMsg := fmt.Sprintf("unable to list the usage updates for user '%s'", username)
+	var err error
+
+	query := db.WithContext(ctx).
+		Preload("UpdateOperation").
+		Joins("JOIN users ON updates.user_id = users.id").
+		Where("users.username = ?", username).
+		Where("updates.value_type = ?", model.ValueTypeUsages).
+		Where("updates.organization_id IS NULL")
+	if since != nil {
+		query = query.Where("updates.effective_date >= ?", *since)
+	}
+	if until != nil {
+		query = query.Where("updates.effective_date < ?", *until)
+	}
+	if resourceTypeID != nil {
+		query = query.Where("updates.resource_type_id = ?", *resourceTypeID)
+	}
+
+	var updates []model.Update
+	err = query.Order("updates.effective_date asc, updates.created_at asc").Find(&updates).Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return updates, nil
+}
+
+// ListUpdatesByID lists the updates with the given identifiers, along with their update operations.
+func ListUpdatesByID(ctx context.Context, db *gorm.DB, updateIDs []string) ([]model.Update, error) {
+	wrapMsg := "unable to list updates by ID"
+	var err error
+
+	var updates []model.Update
+	if len(updateIDs) == 0 {
+		return updates, nil
+	}
+	err = db.WithContext(ctx).Preload("UpdateOperation").Where("id IN ?", updateIDs).Find(&updates).Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return updates, nil
+}
+
+// UpdateListingParams represents the parameters that can be used to customize an update listing.
+type UpdateListingParams struct {
+	Offset         int
+	Limit          int
+	SortDir        string
+	Username       string
+	ValueType      string
+	ResourceTypeID *string
+	MetadataKey    string
+	MetadataValue  *string
+	BatchID        string
+}
+
+// metadataFilterDocuments returns the JSON documents that an update's metadata may contain in order to match the given
+// metadata key and value. The value always matches a string. It also matches a number or boolean if it can be parsed
+// as one, so that numeric identifiers can be found. Containment queries are used so that the metadata index applies.
+func metadataFilterDocuments(key, value string) ([]string, error) {
+	candidates := []any{value}
+
+	// Determine whether or not the value can also be parsed as a number or boolean.
+	var scalar any
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&scalar); err == nil && !decoder.More() && strings.TrimSpace(value) == value {
+		switch scalar.(type) {
+		case json.Number, bool:
+			candidates = append(candidates, scalar)
+		}
+	}
+
+	// Build the documents.
+	documents := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		encoded, err := json.Marshal(map[string]any{key: candidate})
+		if err != nil {
+			return nil, err
+		}
+		documents[i] = string(encoded)
+	}
+
+	return documents, nil
+}
+
+// ListUpdates lists updates that match the given parameters, along with their update operations, resource types, and
+// users. Updates are sorted by effective date. If a metadata key is specified then only updates with that key in their
+// metadata are listed. If a metadata value is also specified then the value associated with the key must be equal to
+// it, either as a string or as a number or boolean. Both kinds of metadata filter are supported by the metadata index.
+// If a batch ID is specified then only updates made by that bulk operation are listed.
+func ListUpdates(ctx context.Context, db *gorm.DB, params *UpdateListingParams) ([]*model.Update, int64, error) {
+	wrapMsg := "unable to list updates"
+	var err error
+
+	// Determine the offset and limit to use.
+	var offset int = 0
+	if params.Offset >= 0 {
+		offset = params.Offset
+	}
+	var limit int = 50
+	if params.Limit >= 0 {
+		limit = params.Limit
+	}
+
+	// Determine the sort order to use.
+	order := "asc"
+	if params.SortDir != "" {
+		order = params.SortDir
+	}
+	orderBy := fmt.Sprintf("updates.effective_date %s, updates.created_at %s", order, order)
+
+	// Build the base query.
+	baseQuery := db.WithContext(ctx).
+		Model(&model.Update{}).
+		Joins("LEFT JOIN users ON updates.user_id = users.id")
+	if params.Username != "" {
+		baseQuery = baseQuery.Where("users.username = ?", params.Username)
+	}
+	if params.ValueType != "" {
+		baseQuery = baseQuery.Where("updates.value_type = ?", params.ValueType)
+	}
+	if params.ResourceTypeID != nil {
+		baseQuery = baseQuery.Where("updates.resource_type_id = ?", *params.ResourceTypeID)
+	}
+	if params.BatchID != "" {
+		baseQuery = baseQuery.Where("updates.batch_id = ?", params.BatchID)
+	}
+	if params.MetadataKey != "" {
+		if params.MetadataValue != nil {
+			documents, err := metadataFilterDocuments(params.MetadataKey, *params.MetadataValue)
+			if err != nil {
+				return nil, 0, errors.Wrap(err, wrapMsg)
+			}
+			condition := db.Where("updates.metadata @> ?::jsonb", documents[0])
+			for _, document := range documents[1:] {
+				condition = condition.Or("updates.metadata @> ?::jsonb", document)
+			}
+			baseQuery = baseQuery.Where(condition)
+		} else {
+			// The key existence operator is also a question mark, so it's passed in as an expression to prevent GORM
+			// from treating it as a placeholder. The operator is used instead of jsonb_exists so that the metadata
+			// index applies.
+			baseQuery = baseQuery.Where("updates.metadata ? ?", clause.Expr{SQL: "?"}, params.MetadataKey)
+		}
+	}
+
+	// Count the number of items in the result set.
+	var count int64
+	err = baseQuery.Count(&count).Error
+	if err != nil {
+		return nil, 0, errors.Wrap(err, wrapMsg)
+	}
+
+	// Look up the result set.
+	var updates []*model.Update
+	err = baseQuery.
+		Preload("UpdateOperation").
+		Preload("ResourceType").
+		Preload("User").
+		Offset(offset).
+		Limit(limit).
+		Order(orderBy).
+		Find(&updates).
+		Error
+	if err != nil {
+		return nil, 0, errors.Wrap(err, wrapMsg)
+	}
+
+	return updates, count, nil
+}
+
+// NewBatchID generates a new identifier that can be used to group the updates made by a single bulk operation.
+func NewBatchID(ctx context.Context, db *gorm.DB) (string, error) {
+	wrapMsg := "unable to generate a batch ID"
+
+	var batchID string
+	err := db.WithContext(ctx).Raw("SELECT uuid_generate_v4()").Scan(&batchID).Error
+	if err != nil {
+		return "", errors.Wrap(err, wrapMsg)
+	}
+
+	return batchID, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -23,3 +24,23 @@ func UpsertUsage(ctx context.Context, db *gorm.DB, usage *model.Usage) error {
 		UpdateAll: true,
 	}).Create(&usage).Error
 }
+
+// GetUsageValue returns the current usage amount for a resource type within a subscription, or zero if no usage has
+// been recorded for the resource type.
+func GetUsageValue(ctx context.Context, db *gorm.DB, subscriptionID, resourceTypeID string) (float64, error) {
+	wrapMsg := "unable to look up the current usage"
+
+	var usage float64
+	err := db.WithContext(ctx).
+		Model(&model.Usage{}).
+		Select("COALESCE(sum(usage), 0)").
+		Where("subscription_id = ?", subscriptionID).
+		Where("resource_type_id = ?", resourceTypeID).
+		Scan(&usage).
+		Error
+	if err != nil {
+		return 0, errors.Wrap(err, wrapMsg)
+	}
+
+	return usage, nil
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/cyverse/qms/internal/model"
 	"github.com/pkg/errors"
@@ -29,6 +30,22 @@ func GetUser(ctx context.Context, db *gorm.DB, username string) (*model.User, er
 	return &user, nil
 }
 
+// GetUserByID looks up the user with the given identifier. A nil pointer is returned if the user doesn't exist.
+func GetUserByID(ctx context.Context, db *gorm.DB, userID string) (*model.User, error) {
+	wrapMsg := fmt.Sprintf("unable to look up user ID '%s'", userID)
+	var err error
+
+	var user model.User
+	err = db.WithContext(ctx).Where("id = ?", userID).First(&user).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return &user, nil
+}
+
 // UserExists determines whether or not the user exists in the database.
 func UserExists(ctx context.Context, db *gorm.DB, username string) (bool, error) {
 	wrapMsg := "unable to determine whether user exists"
@@ -43,3 +60,17 @@ func UserExists(ctx context.Context, db *gorm.DB, username string) (bool, error)
 	}
 	return true, nil
 }
+
+// ListUsernames lists the usernames of all users in the database in alphabetical order.
+func ListUsernames(ctx context.Context, db *gorm.DB) ([]string, error) {
+	wrapMsg := "unable to list usernames"
+	var err error
+
+	var usernames []string
+	err = db.WithContext(ctx).Model(&model.User{}).Order("username asc").Pluck("username", &usernames).Error
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+
+	return usernames, nil
+}
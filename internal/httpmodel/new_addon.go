@@ -0,0 +1,248 @@
+package httpmodel
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+)
+
+// NewAddon
+//
+// swagger:model
+type NewAddon struct {
+
+	// The addon name
+	//
+	// required: true
+	Name string `json:"name"`
+
+	// A brief description of the addon
+	//
+	// required: true
+	Description string `json:"description"`
+
+	// The resource type whose quota is increased by the addon
+	//
+	// required: true
+	ResourceType NewPlanResourceType `json:"resource_type"`
+
+	// The amount that the quota is increased by default
+	//
+	// required: true
+	DefaultAmount float64 `json:"default_amount"`
+
+	// True if the addon is paid for by default; defaults to true
+	DefaultPaid *bool `json:"default_paid"`
+
+	// The rates associated with the addon
+	AddonRates []NewAddonRate `json:"addon_rates"`
+}
+
+// Validate verifies that all the required fields in a new addon are present.
+func (a NewAddon) Validate() error {
+	var err error
+
+	// The addon name and description are both required.
+	if a.Name == "" {
+		return fmt.Errorf("an addon name is required")
+	}
+	if a.Description == "" {
+		return fmt.Errorf("an addon description is required")
+	}
+
+	// The default amount must be specified.
+	if a.DefaultAmount <= 0 {
+		return fmt.Errorf("the default amount must be specified and greater than zero")
+	}
+
+	// The resource type must be specified.
+	if err = a.ResourceType.Validate(); err != nil {
+		return err
+	}
+
+	// Validate each of the addon rates.
+	for _, ar := range a.AddonRates {
+		err = ar.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Verify that the effective date is unique for all of the addon rates.
+	uniqueAddonRates := make(map[int64]bool)
+	for _, ar := range a.AddonRates {
+		if uniqueAddonRates[ar.EffectiveDate.UnixMilli()] {
+			return fmt.Errorf("multiple addon rates found with the same effective date")
+		}
+		uniqueAddonRates[ar.EffectiveDate.UnixMilli()] = true
+	}
+
+	return nil
+}
+
+// ToDBModel converts an addon to its equivalent database model.
+func (a NewAddon) ToDBModel() model.Addon {
+
+	// Convert each of the addon rates.
+	addonRates := make([]model.AddonRate, len(a.AddonRates))
+	for i, addonRate := range a.AddonRates {
+		addonRates[i] = addonRate.ToDBModel()
+	}
+
+	// The addon is paid for by default unless we're told otherwise.
+	defaultPaid := true
+	if a.DefaultPaid != nil {
+		defaultPaid = *a.DefaultPaid
+	}
+
+	return model.Addon{
+		Name:          a.Name,
+		Description:   a.Description,
+		ResourceType:  a.ResourceType.ToDBModel(),
+		DefaultAmount: a.DefaultAmount,
+		DefaultPaid:   defaultPaid,
+		AddonRates:    addonRates,
+	}
+}
+
+// UpdatedAddon
+//
+// swagger:model
+type UpdatedAddon struct {
+
+	// The addon name
+	//
+	// required: true
+	Name string `json:"name"`
+
+	// A brief description of the addon
+	//
+	// required: true
+	Description string `json:"description"`
+
+	// The resource type whose quota is increased by the addon
+	//
+	// required: true
+	ResourceType NewPlanResourceType `json:"resource_type"`
+
+	// The amount that the quota is increased by default
+	//
+	// required: true
+	DefaultAmount float64 `json:"default_amount"`
+
+	// True if the addon is paid for by default
+	//
+	// required: true
+	DefaultPaid *bool `json:"default_paid"`
+}
+
+// Validate verifies that all the required fields in an updated addon are present.
+func (a UpdatedAddon) Validate() error {
+
+	// The addon name and description are both required.
+	if a.Name == "" {
+		return fmt.Errorf("an addon name is required")
+	}
+	if a.Description == "" {
+		return fmt.Errorf("an addon description is required")
+	}
+
+	// The default amount must be specified.
+	if a.DefaultAmount <= 0 {
+		return fmt.Errorf("the default amount must be specified and greater than zero")
+	}
+
+	// The default paid flag must be specified.
+	if a.DefaultPaid == nil {
+		return fmt.Errorf("the default paid flag is required")
+	}
+
+	return a.ResourceType.Validate()
+}
+
+// NewAddonRate
+//
+// swagger:model
+type NewAddonRate struct {
+
+	// The date when the addon rate becomes effective
+	//
+	// required: true
+	EffectiveDate time.Time `json:"effective_date"`
+
+	// The rate
+	//
+	// required: true
+	Rate float64 `json:"rate"`
+}
+
+// Validate verifies that all addon rate fields are valid.
+func (ar NewAddonRate) Validate() error {
+
+	// The rate can't be negative.
+	if ar.Rate < 0 {
+		return fmt.Errorf("the addon rate must not be less than zero")
+	}
+
+	// The effective date has to be specified.
+	if ar.EffectiveDate.IsZero() {
+		return fmt.Errorf("the effective date of the addon rate must be specified")
+	}
+
+	return nil
+}
+
+// ToDBModel converts an addon rate to its equivalent database model.
+func (ar NewAddonRate) ToDBModel() model.AddonRate {
+	return model.AddonRate{
+		EffectiveDate: ar.EffectiveDate,
+		Rate:          ar.Rate,
+	}
+}
+
+// NewAddonRateList
+//
+// swagger:model
+type NewAddonRateList struct {
+
+	// The list of addon rates.
+	//
+	// required: true
+	AddonRates []NewAddonRate `json:"addon_rates"`
+}
+
+// Validate verifies that all of the addon rates in the list are valid.
+func (arl *NewAddonRateList) Validate() error {
+
+	// Validate each of the addon rates.
+	for _, ar := range arl.AddonRates {
+		err := ar.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check for multiple addon rates with the same effective date.
+	uniqueAddonRates := make(map[int64]bool)
+	for _, ar := range arl.AddonRates {
+		if uniqueAddonRates[ar.EffectiveDate.UnixMilli()] {
+			return fmt.Errorf("multiple addon rates found with the same effective date")
+		}
+		uniqueAddonRates[ar.EffectiveDate.UnixMilli()] = true
+	}
+
+	return nil
+}
+
+// ToDBModel converts a list of addon rates to their equivalent database models.
+func (arl *NewAddonRateList) ToDBModel() []model.AddonRate {
+
+	// Convert each addon rate in the list to its corresponding database model.
+	addonRates := make([]model.AddonRate, len(arl.AddonRates))
+	for i, ar := range arl.AddonRates {
+		addonRates[i] = ar.ToDBModel()
+	}
+
+	return addonRates
+}
@@ -127,6 +127,36 @@ func (p NewPlan) ToDBModel() model.Plan {
 	}
 }
 
+// UpdatedPlan
+//
+// swagger:model
+type UpdatedPlan struct {
+
+	// The plan name
+	//
+	// required: true
+	Name string `json:"name"`
+
+	// A brief description of the plan
+	//
+	// required: true
+	Description string `json:"description"`
+}
+
+// Validate verifies that all the required fields in an updated plan are present.
+func (p UpdatedPlan) Validate() error {
+
+	// The plan name and description are both required.
+	if p.Name == "" {
+		return fmt.Errorf("a plan name is required")
+	}
+	if p.Description == "" {
+		return fmt.Errorf("a plan description is required")
+	}
+
+	return nil
+}
+
 // NewPlanQuotaDefaultList
 //
 // swagger:model
@@ -0,0 +1,49 @@
+package httpmodel
+
+import "github.com/cyverse/qms/internal/model"
+
+// NewOrganization represents a request to add a new organization.
+//
+// swagger:model
+type NewOrganization struct {
+	// The organization name
+	//
+	// required: true
+	Name string `json:"name" validate:"required"`
+
+	// A brief description of the organization
+	Description string `json:"description"`
+}
+
+// OrganizationMembership represents a request to add a user to an organization or to change the user's role.
+//
+// swagger:model
+type OrganizationMembership struct {
+	// The member's role in the organization: owner, admin, or member
+	//
+	// required: true
+	Role string `json:"role" validate:"required,oneof=owner admin member"`
+}
+
+// OrganizationMemberCap represents a request to limit the amount of a resource that a member may consume from the
+// organization's pool.
+//
+// swagger:model
+type OrganizationMemberCap struct {
+	// The maximum amount of the resource that the member may consume
+	//
+	// required: true
+	Cap float64 `json:"cap" validate:"gte=0"`
+}
+
+// NewOrganizationSubscription represents a request to subscribe an organization to a plan.
+//
+// swagger:model
+type NewOrganizationSubscription struct {
+	model.SubscriptionOptions
+
+	// The name of the plan
+	//
+	// required: true
+	PlanName string `json:"plan_name" validate:"required"`
+}
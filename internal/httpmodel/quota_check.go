@@ -0,0 +1,16 @@
+package httpmodel
+
+// QuotaCheckRequest represents a request to determine whether or not a user may consume a resource.
+//
+// swagger:model
+type QuotaCheckRequest struct {
+	// The name of the resource type
+	//
+	// required: true
+	ResourceTypeName string `json:"resource_type" validate:"required"`
+
+	// The amount of the resource that the caller intends to consume
+	//
+	// required: true
+	Amount float64 `json:"amount" validate:"gte=0"`
+}
@@ -0,0 +1,61 @@
+package httpmodel
+
+// The modes in which a bulk quota grant may be applied.
+const (
+	QuotaGrantModeAdd = "add"
+	QuotaGrantModeSet = "set"
+)
+
+// QuotaGrant represents a request to change the same quota for several users at once.
+//
+// swagger:model
+type QuotaGrant struct {
+	// The usernames of the users receiving the grant
+	//
+	// required: true
+	Usernames []string `json:"usernames" validate:"required,min=1,dive,required"`
+
+	// The name of the resource type
+	//
+	// required: true
+	ResourceTypeName string `json:"resource_type" validate:"required"`
+
+	// The amount to add to each quota, or the value to set each quota to
+	//
+	// required: true
+	Amount float64 `json:"amount" validate:"gte=0"`
+
+	// Whether the amount is added to each quota or each quota is set to the amount
+	//
+	// required: true
+	// enum: ["add","set"]
+	Mode string `json:"mode" validate:"required,oneof=add set"`
+}
+
+// QuotaGrantResult represents the result of a bulk quota grant for a single user.
+//
+// swagger:model
+type QuotaGrantResult struct {
+	// The username
+	Username string `json:"username"`
+
+	// True if the user's quota was changed
+	Success bool `json:"success"`
+
+	// The user's quota after the change was applied
+	Quota *float64 `json:"quota,omitempty"`
+
+	// The reason the user's quota couldn't be changed if an error occurred
+	FailureReason *string `json:"failure_reason,omitempty"`
+}
+
+// QuotaGrantReport represents the results of a bulk quota grant.
+//
+// swagger:model
+type QuotaGrantReport struct {
+	// The identifier recorded with every quota update made by the grant
+	BatchID string `json:"batch_id"`
+
+	// The result for each user, in the same order as the request
+	Results []*QuotaGrantResult `json:"results"`
+}
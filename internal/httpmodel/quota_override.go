@@ -0,0 +1,49 @@
+package httpmodel
+
+import (
+	"time"
+
+	"github.com/cyverse/qms/internal/model/timestamp"
+)
+
+// NewQuotaOverride represents a request to temporarily increase a quota in a user's subscription.
+//
+// swagger:model
+type NewQuotaOverride struct {
+	// The name of the resource type
+	//
+	// required: true
+	ResourceTypeName string `json:"resource_type" validate:"required"`
+
+	// The amount by which the quota is increased
+	//
+	// required: true
+	Amount float64 `json:"amount" validate:"gt=0"`
+
+	// The reason for the quota override
+	//
+	// required: true
+	Reason string `json:"reason" validate:"required"`
+
+	// The date and time the quota override becomes active; defaults to the current time
+	EffectiveStartDate *timestamp.Timestamp `json:"effective_start_date"`
+
+	// The date and time the quota override expires
+	//
+	// required: true
+	EffectiveEndDate *timestamp.Timestamp `json:"effective_end_date" validate:"required"`
+}
+
+// GetEffectiveStartDate returns the date and time the quota override becomes active, falling back to the given time if
+// no start date was specified in the request.
+func (o *NewQuotaOverride) GetEffectiveStartDate(now time.Time) time.Time {
+	if o.EffectiveStartDate == nil {
+		return now
+	}
+	return time.Time(*o.EffectiveStartDate)
+}
+
+// GetEffectiveEndDate returns the date and time the quota override expires.
+func (o *NewQuotaOverride) GetEffectiveEndDate() time.Time {
+	return time.Time(*o.EffectiveEndDate)
+}
@@ -0,0 +1,45 @@
+package httpmodel
+
+import (
+	"time"
+
+	"github.com/cyverse/qms/internal/model/timestamp"
+)
+
+// NewReservation represents a request to place a hold on part of a user's remaining allowance for a resource type.
+//
+// swagger:model
+type NewReservation struct {
+	// The name of the resource type
+	//
+	// required: true
+	ResourceTypeName string `json:"resource_type" validate:"required"`
+
+	// The estimated amount of the resource that the caller intends to consume
+	//
+	// required: true
+	Amount float64 `json:"amount" validate:"gt=0"`
+
+	// The date and time when the hold expires; defaults to the configured reservation lifetime
+	ExpiresAt *timestamp.Timestamp `json:"expires_at"`
+}
+
+// GetExpiresAt returns the expiration time of the reservation, falling back to the given lifetime if no expiration time
+// was specified in the request.
+func (r *NewReservation) GetExpiresAt(now time.Time, lifetime time.Duration) time.Time {
+	if r.ExpiresAt == nil {
+		return now.Add(lifetime)
+	}
+	return time.Time(*r.ExpiresAt)
+}
+
+// ReservationCommit represents a request to record the amount of a resource that was actually consumed for a
+// reservation.
+//
+// swagger:model
+type ReservationCommit struct {
+	// The amount of the resource that was actually consumed
+	//
+	// required: true
+	Amount float64 `json:"amount" validate:"gte=0"`
+}
@@ -0,0 +1,63 @@
+package httpmodel
+
+import (
+	"fmt"
+
+	"github.com/cyverse/qms/internal/model"
+)
+
+// NewSubscriptionAddon
+//
+// swagger:model
+type NewSubscriptionAddon struct {
+
+	// The identifier of the addon to apply to the subscription
+	//
+	// required: true
+	AddonID string `json:"addon_id"`
+
+	// The amount to increase the quota by; defaults to the default amount for the addon
+	Amount *float64 `json:"amount"`
+
+	// True if the user paid for the addon; defaults to the default paid flag for the addon
+	Paid *bool `json:"paid"`
+}
+
+// Validate verifies that all the required fields in a new subscription addon are present.
+func (sa NewSubscriptionAddon) Validate() error {
+
+	// The addon ID is required.
+	if sa.AddonID == "" {
+		return fmt.Errorf("an addon ID is required")
+	}
+
+	// The amount must be positive if it's specified.
+	if sa.Amount != nil && *sa.Amount <= 0 {
+		return fmt.Errorf("the amount must be greater than zero")
+	}
+
+	return nil
+}
+
+// ToDBModel converts a subscription addon to its equivalent database model, using the addon defaults for any values
+// that weren't specified in the request.
+func (sa NewSubscriptionAddon) ToDBModel(
+	subscriptionID string, addon *model.Addon, addonRate *model.AddonRate,
+) model.SubscriptionAddon {
+	amount := addon.DefaultAmount
+	if sa.Amount != nil {
+		amount = *sa.Amount
+	}
+	paid := addon.DefaultPaid
+	if sa.Paid != nil {
+		paid = *sa.Paid
+	}
+
+	return model.SubscriptionAddon{
+		SubscriptionID: &subscriptionID,
+		AddonID:        addon.ID,
+		Amount:         amount,
+		Paid:           paid,
+		AddonRateID:    addonRate.ID,
+	}
+}
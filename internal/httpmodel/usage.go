@@ -0,0 +1,101 @@
+package httpmodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/cyverse/qms/internal/model/timestamp"
+)
+
+// Usage represents a request to update the usage value for a user and resource type.
+//
+// swagger:model
+type Usage struct {
+	// The username
+	//
+	// required: true
+	Username string `json:"username"`
+
+	// The name of the resource type
+	//
+	// required: true
+	ResourceName string `json:"resource_name"`
+
+	// The usage value
+	//
+	// required: true
+	UsageValue float64 `json:"usage_value"`
+
+	// The type of update to perform: SET, ADD, SUBTRACT, or REVERSE. The usage value is ignored for REVERSE updates.
+	//
+	// required: true
+	UpdateType string `json:"update_type"`
+
+	// The ID of the update that a SUBTRACT or REVERSE update corrects. This field is required for REVERSE updates.
+	OriginalUpdateID string `json:"original_update_id,omitempty"`
+
+	// Optional metadata to record with the update. The metadata must be a JSON object, or a string containing an
+	// encoded JSON object.
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+
+	// The time at which the update takes effect. The update is applied to the subscription that was active at that
+	// time. Defaults to the current time.
+	EffectiveDate *timestamp.Timestamp `json:"effective_date,omitempty"`
+
+	// An optional key that identifies the request so that it can be safely retried. The Idempotency-Key request
+	// header may be used instead for single usage updates.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+}
+
+// RequestHash returns a hash of the fields in a usage update request that determine its effect. Retries of a request
+// with the same idempotency key must have the same request hash. The username is passed in separately so that the
+// username suffix can be removed before the hash is computed.
+func (u *Usage) RequestHash(username string) string {
+	fields := []any{username, u.ResourceName, u.UsageValue, u.UpdateType, u.Metadata}
+	if u.OriginalUpdateID != "" {
+		fields = append(fields, u.OriginalUpdateID)
+	}
+	if u.EffectiveDate != nil {
+		fields = append(fields, time.Time(*u.EffectiveDate).UTC().Format(time.RFC3339Nano))
+	}
+	encoded, _ := json.Marshal(fields)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// GetEffectiveDate returns the time at which the update takes effect, or nil if no effective date was specified.
+func (u *Usage) GetEffectiveDate() *time.Time {
+	if u.EffectiveDate == nil {
+		return nil
+	}
+	effectiveDate := time.Time(*u.EffectiveDate)
+	return &effectiveDate
+}
+
+// UsageBatch represents a request to apply multiple usage updates.
+//
+// swagger:model
+type UsageBatch struct {
+	// The list of usage updates to apply
+	//
+	// required: true
+	Usages []Usage `json:"usages"`
+}
+
+// UsageResult represents the result of a single usage update in a batch.
+//
+// swagger:model
+type UsageResult struct {
+	Usage
+
+	// True if the usage update was applied
+	Success bool `json:"success"`
+
+	// True if the usage update was applied by an earlier request with the same idempotency key
+	Replayed bool `json:"replayed,omitempty"`
+
+	// The reason the usage update couldn't be applied if an error occurred
+	FailureReason *string `json:"failure_reason,omitempty"`
+}
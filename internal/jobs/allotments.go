@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/cyverse/qms/internal/quotas"
+	"gorm.io/gorm"
+)
+
+// AllotmentRolloverInterval is the amount of time to wait between checks for allotment periods that have ended.
+var AllotmentRolloverInterval = time.Minute
+
+// RolloverAllotmentPeriods returns a job that periodically begins new allotment periods for subscriptions in allotment
+// mode whose current periods have ended, resetting their consumable usages.
+func RolloverAllotmentPeriods(gormdb *gorm.DB) Job {
+	return Job{
+		Name:     "roll over allotment periods",
+		Interval: AllotmentRolloverInterval,
+		Run: func(ctx context.Context) error {
+			count, err := quotas.ProcessDueRollovers(ctx, gormdb, time.Now())
+			if count > 0 {
+				log.Infof("rolled over %d allotment periods", count)
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/cyverse/qms/logging"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.GetLogger().WithFields(logrus.Fields{"package": "jobs"})
+
+// Job represents a task that runs periodically in the background.
+type Job struct {
+	// The name of the job, which is used in log messages.
+	Name string
+
+	// The amount of time to wait between runs.
+	Interval time.Duration
+
+	// The function that performs the task.
+	Run func(ctx context.Context) error
+}
+
+// run runs a single job periodically until the context is canceled. Errors are logged, but they don't stop the job
+// from being run again.
+func run(ctx context.Context, job Job) {
+	log := log.WithFields(logrus.Fields{"context": "background job", "job": job.Name})
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("stopping the job")
+			return
+		case <-ticker.C:
+			if err := job.Run(ctx); err != nil {
+				log.Errorf("job failed: %s", err.Error())
+			}
+		}
+	}
+}
+
+// Start runs each of the given jobs periodically in its own goroutine until the context is canceled.
+func Start(ctx context.Context, jobs ...Job) {
+	for _, job := range jobs {
+		go run(ctx, job)
+	}
+}
@@ -0,0 +1,16 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/cyverse/qms/internal/outbox"
+)
+
+// DispatchOutboxEvents returns a job that periodically delivers pending outbox events using the given dispatcher.
+func DispatchOutboxEvents(dispatcher *outbox.Dispatcher, interval time.Duration) Job {
+	return Job{
+		Name:     "dispatch outbox events",
+		Interval: interval,
+		Run:      dispatcher.DispatchPending,
+	}
+}
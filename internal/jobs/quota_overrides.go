@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/cyverse/qms/internal/quotas"
+	"gorm.io/gorm"
+)
+
+// QuotaOverrideInterval is the amount of time to wait between checks for quota overrides that are due to be activated
+// or retired.
+var QuotaOverrideInterval = time.Minute
+
+// ProcessQuotaOverrides returns a job that periodically activates quota overrides whose start dates have passed and
+// retires quota overrides whose end dates have passed. Each change to a quota is recorded in the updates ledger.
+func ProcessQuotaOverrides(gormdb *gorm.DB) Job {
+	return Job{
+		Name:     "process quota overrides",
+		Interval: QuotaOverrideInterval,
+		Run: func(ctx context.Context) error {
+			activated, retired, err := quotas.ProcessDueOverrides(ctx, gormdb, time.Now())
+			if activated > 0 {
+				log.Infof("activated %d quota overrides", activated)
+			}
+			if retired > 0 {
+				log.Infof("retired %d quota overrides", retired)
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"gorm.io/gorm"
+)
+
+// ReservationExpirationInterval is the amount of time to wait between checks for expired reservations.
+var ReservationExpirationInterval = time.Minute
+
+// ExpireReservations returns a job that periodically marks holds whose expiration time has passed as expired. Expired
+// holds are already excluded from the remaining allowance calculations, so this job only keeps the reservation status
+// up to date.
+func ExpireReservations(gormdb *gorm.DB) Job {
+	return Job{
+		Name:     "expire reservations",
+		Interval: ReservationExpirationInterval,
+		Run: func(ctx context.Context) error {
+			count, err := db.ExpireReservations(ctx, gormdb)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				log.Infof("marked %d reservations as expired", count)
+			}
+			return nil
+		},
+	}
+}
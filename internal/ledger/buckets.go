@@ -0,0 +1,78 @@
+package ledger
+
+import (
+	"fmt"
+	"time"
+)
+
+// Interval constants.
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// Intervals lists the supported bucket intervals.
+var Intervals = []string{IntervalDay, IntervalWeek, IntervalMonth}
+
+// MaxBuckets is the maximum number of buckets that may be generated for a single time range.
+var MaxBuckets = 1000
+
+// Bucket represents a time range in a time series. The start time is inclusive and the end time is exclusive.
+type Bucket struct {
+	Start time.Time
+	End   time.Time
+}
+
+// startOfInterval returns the start of the interval containing the given time. Weeks start on Monday.
+func startOfInterval(t time.Time, interval string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch interval {
+	case IntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	default:
+		return day
+	}
+}
+
+// nextInterval returns the start of the interval following the interval that starts at the given time.
+func nextInterval(start time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Buckets divides the time range from the start of the interval containing from up to, but not including, to into
+// buckets of the given interval. The end of the last bucket is truncated to the end of the time range.
+func Buckets(from, to time.Time, interval string) ([]Bucket, error) {
+	switch interval {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+	default:
+		return nil, fmt.Errorf("unsupported interval: %s", interval)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("the start of the time range must be before the end of the time range")
+	}
+
+	var buckets []Bucket
+	for start := startOfInterval(from, interval); start.Before(to); start = nextInterval(start, interval) {
+		if len(buckets) >= MaxBuckets {
+			return nil, fmt.Errorf("the time range may contain at most %d buckets", MaxBuckets)
+		}
+		end := nextInterval(start, interval)
+		if end.After(to) {
+			end = to
+		}
+		buckets = append(buckets, Bucket{Start: start, End: end})
+	}
+
+	return buckets, nil
+}
@@ -0,0 +1,140 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+)
+
+// date returns midnight UTC on the given date.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBuckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		interval string
+		expected []Bucket
+	}{
+		{
+			name:     "days starting mid-day",
+			from:     date(2024, time.January, 1).Add(10 * time.Hour),
+			to:       date(2024, time.January, 4),
+			interval: IntervalDay,
+			expected: []Bucket{
+				{date(2024, time.January, 1), date(2024, time.January, 2)},
+				{date(2024, time.January, 2), date(2024, time.January, 3)},
+				{date(2024, time.January, 3), date(2024, time.January, 4)},
+			},
+		},
+		{
+			name:     "weeks starting mid-week",
+			from:     date(2024, time.January, 3),
+			to:       date(2024, time.January, 20),
+			interval: IntervalWeek,
+			expected: []Bucket{
+				{date(2024, time.January, 1), date(2024, time.January, 8)},
+				{date(2024, time.January, 8), date(2024, time.January, 15)},
+				{date(2024, time.January, 15), date(2024, time.January, 20)},
+			},
+		},
+		{
+			name:     "weeks starting on a Sunday",
+			from:     date(2024, time.January, 7),
+			to:       date(2024, time.January, 8),
+			interval: IntervalWeek,
+			expected: []Bucket{
+				{date(2024, time.January, 1), date(2024, time.January, 8)},
+			},
+		},
+		{
+			name:     "weeks starting on a Monday",
+			from:     date(2024, time.January, 8),
+			to:       date(2024, time.January, 9),
+			interval: IntervalWeek,
+			expected: []Bucket{
+				{date(2024, time.January, 8), date(2024, time.January, 9)},
+			},
+		},
+		{
+			name:     "months",
+			from:     date(2024, time.January, 15),
+			to:       date(2024, time.March, 10),
+			interval: IntervalMonth,
+			expected: []Bucket{
+				{date(2024, time.January, 1), date(2024, time.February, 1)},
+				{date(2024, time.February, 1), date(2024, time.March, 1)},
+				{date(2024, time.March, 1), date(2024, time.March, 10)},
+			},
+		},
+		{
+			name:     "months ending at the start of a month",
+			from:     date(2024, time.December, 31),
+			to:       date(2025, time.February, 1),
+			interval: IntervalMonth,
+			expected: []Bucket{
+				{date(2024, time.December, 1), date(2025, time.January, 1)},
+				{date(2025, time.January, 1), date(2025, time.February, 1)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := Buckets(test.from, test.to, test.interval)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(actual) != len(test.expected) {
+				t.Fatalf("expected %d buckets, got %d: %v", len(test.expected), len(actual), actual)
+			}
+			for i := range actual {
+				if !actual[i].Start.Equal(test.expected[i].Start) || !actual[i].End.Equal(test.expected[i].End) {
+					t.Errorf("bucket %d: expected %v, got %v", i, test.expected[i], actual[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBucketsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		interval string
+	}{
+		{"unsupported interval", date(2024, time.January, 1), date(2024, time.January, 2), "year"},
+		{"empty time range", date(2024, time.January, 1), date(2024, time.January, 1), IntervalDay},
+		{"reversed time range", date(2024, time.January, 2), date(2024, time.January, 1), IntervalDay},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Buckets(test.from, test.to, test.interval)
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestBucketsMaxBuckets(t *testing.T) {
+	defer func(maxBuckets int) { MaxBuckets = maxBuckets }(MaxBuckets)
+	MaxBuckets = 3
+
+	buckets, err := Buckets(date(2024, time.January, 1), date(2024, time.January, 4), IntervalDay)
+	if err != nil {
+		t.Fatalf("expected %d buckets to be allowed: %s", MaxBuckets, err)
+	}
+	if len(buckets) != 3 {
+		t.Errorf("expected 3 buckets, got %d", len(buckets))
+	}
+
+	_, err = Buckets(date(2024, time.January, 1), date(2024, time.January, 5), IntervalDay)
+	if err == nil {
+		t.Errorf("expected more than %d buckets to be rejected", MaxBuckets)
+	}
+}
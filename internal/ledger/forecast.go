@@ -0,0 +1,93 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+)
+
+// MaxForecastDays is the largest number of days into the future for which an exhaustion date is projected.
+var MaxForecastDays = 36500.0
+
+// DailyRate computes the average amount of a resource consumed per day between the start and end of a time window.
+// Consumption is measured by ADD updates. SUBTRACT and REVERSE updates, which refund consumed resources, are deducted.
+// SET updates are ignored because they replace usage values rather than record consumption, and so are reversals of
+// SET updates. A reversal is only recognized as the reversal of a SET update if the original update is included in
+// the list of updates, but the original update doesn't have to fall within the time window. Be careful to ensure that
+// the update operation has been loaded for each update before calling this function.
+func DailyRate(updates []model.Update, windowStart, windowEnd time.Time) float64 {
+	days := windowEnd.Sub(windowStart).Hours() / 24
+	if days <= 0 {
+		return 0
+	}
+
+	// Index the update operations so that reversals of SET updates can be recognized.
+	operationNames := make(map[string]string)
+	for _, update := range updates {
+		if update.ID != nil {
+			operationNames[*update.ID] = update.UpdateOperation.Name
+		}
+	}
+
+	var consumed float64
+	for _, update := range updates {
+		if update.EffectiveDate.Before(windowStart) || !update.EffectiveDate.Before(windowEnd) {
+			continue
+		}
+		switch update.UpdateOperation.Name {
+		case model.UpdateOperationAdd:
+			consumed += update.Value
+		case model.UpdateOperationSubtract:
+			consumed -= update.Value
+		case model.UpdateOperationReverse:
+			if update.OriginalUpdateID == nil || operationNames[*update.OriginalUpdateID] != model.UpdateOperationSet {
+				consumed -= update.Value
+			}
+		}
+	}
+	if consumed <= 0 {
+		return 0
+	}
+
+	return consumed / days
+}
+
+// ForecastResource projects when the usage of a resource will reach the quota in a subscription if the resource
+// continues to be consumed at the given daily rate. Be careful to ensure that all of the subscription details have been
+// loaded before calling this function.
+func ForecastResource(
+	subscription *model.Subscription, quota *model.Quota, dailyRate float64, now time.Time,
+) model.ResourceForecast {
+	forecast := model.ResourceForecast{
+		ResourceType: quota.ResourceType.Name,
+		Unit:         quota.ResourceType.Unit,
+		Quota:        quota.Quota,
+		Usage:        subscription.GetCurrentUsageValue(*quota.ResourceTypeID),
+		DailyRate:    dailyRate,
+	}
+	forecast.Remaining = forecast.Quota - forecast.Usage
+	if forecast.Remaining < 0 {
+		forecast.Remaining = 0
+	}
+
+	// The exhaustion date can't be projected unless the resource is being consumed or the quota is already reached.
+	if dailyRate <= 0 && forecast.Remaining > 0 {
+		return forecast
+	}
+
+	// Project the exhaustion date, unless it's too far in the future to be meaningful.
+	daysRemaining := 0.0
+	if forecast.Remaining > 0 {
+		daysRemaining = forecast.Remaining / dailyRate
+	}
+	if daysRemaining > MaxForecastDays {
+		return forecast
+	}
+	exhaustionDate := now.Add(time.Duration(daysRemaining * 24 * float64(time.Hour)))
+	forecast.DaysRemaining = &daysRemaining
+	forecast.ExhaustionDate = &exhaustionDate
+	periodEnd := subscription.GetCurrentPeriodEnd()
+	forecast.ExhaustedBeforeEnd = periodEnd == nil || exhaustionDate.Before(*periodEnd)
+
+	return forecast
+}
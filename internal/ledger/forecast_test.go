@@ -0,0 +1,274 @@
+package ledger
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+)
+
+// withID returns a copy of an update with the given identifier.
+func withID(update model.Update, id string) model.Update {
+	update.ID = &id
+	return update
+}
+
+// reversing returns a copy of an update that reverses the update with the given identifier.
+func reversing(update model.Update, originalUpdateID string) model.Update {
+	update.OriginalUpdateID = &originalUpdateID
+	return update
+}
+
+func TestDailyRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		updates     []model.Update
+		windowStart time.Time
+		windowEnd   time.Time
+		expected    float64
+	}{
+		{
+			name: "adds",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 10, day(1)),
+				testUpdate(model.UpdateOperationAdd, 10, day(5)),
+			},
+			windowStart: day(0),
+			windowEnd:   day(10),
+			expected:    2,
+		},
+		{
+			name: "refunds",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 30, day(1)),
+				testUpdate(model.UpdateOperationSubtract, 5, day(2)),
+				testUpdate(model.UpdateOperationReverse, 5, day(3)),
+			},
+			windowStart: day(0),
+			windowEnd:   day(10),
+			expected:    2,
+		},
+		{
+			name: "sets are ignored",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 10, day(1)),
+				testUpdate(model.UpdateOperationSet, 500, day(2)),
+			},
+			windowStart: day(0),
+			windowEnd:   day(10),
+			expected:    1,
+		},
+		{
+			name: "reversals of sets are ignored",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 10, day(1)),
+				withID(testUpdate(model.UpdateOperationSet, 500, day(2)), "set"),
+				reversing(testUpdate(model.UpdateOperationReverse, 490, day(3)), "set"),
+			},
+			windowStart: day(0),
+			windowEnd:   day(10),
+			expected:    1,
+		},
+		{
+			name: "reversals of sets from before the window are ignored",
+			updates: []model.Update{
+				withID(testUpdate(model.UpdateOperationSet, 500, day(-5)), "set"),
+				testUpdate(model.UpdateOperationAdd, 10, day(1)),
+				reversing(testUpdate(model.UpdateOperationReverse, 500, day(3)), "set"),
+			},
+			windowStart: day(0),
+			windowEnd:   day(10),
+			expected:    1,
+		},
+		{
+			name: "reversals of adds from before the window are refunds",
+			updates: []model.Update{
+				withID(testUpdate(model.UpdateOperationAdd, 5, day(-5)), "add"),
+				testUpdate(model.UpdateOperationAdd, 15, day(1)),
+				reversing(testUpdate(model.UpdateOperationReverse, 5, day(3)), "add"),
+			},
+			windowStart: day(0),
+			windowEnd:   day(10),
+			expected:    1,
+		},
+		{
+			name: "updates outside of the window are ignored",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 100, day(-1)),
+				testUpdate(model.UpdateOperationAdd, 10, day(0)),
+				testUpdate(model.UpdateOperationAdd, 100, day(10)),
+			},
+			windowStart: day(0),
+			windowEnd:   day(10),
+			expected:    1,
+		},
+		{
+			name: "more refunds than consumption",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 5, day(1)),
+				testUpdate(model.UpdateOperationSubtract, 10, day(2)),
+			},
+			windowStart: day(0),
+			windowEnd:   day(10),
+			expected:    0,
+		},
+		{
+			name: "empty window",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 5, day(1)),
+			},
+			windowStart: day(1),
+			windowEnd:   day(1),
+			expected:    0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := DailyRate(test.updates, test.windowStart, test.windowEnd)
+			if math.Abs(actual-test.expected) > 1e-9 {
+				t.Errorf("expected %g, got %g", test.expected, actual)
+			}
+		})
+	}
+}
+
+// forecastSubscription returns a subscription ending on the given day with the given usage for a single resource type,
+// along with the quota for that resource type.
+func forecastSubscription(quotaValue, usageValue float64, end time.Time) (*model.Subscription, *model.Quota) {
+	resourceTypeID := "cpu"
+	resourceType := model.ResourceType{ID: &resourceTypeID, Name: "cpu.hours", Unit: "cpu hours", Consumable: true}
+	quota := model.Quota{Quota: quotaValue, ResourceTypeID: &resourceTypeID, ResourceType: resourceType}
+	usage := model.Usage{Usage: usageValue, ResourceTypeID: &resourceTypeID, ResourceType: resourceType}
+
+	subscription := testSubscription("subscription", day(0), end)
+	subscription.Quotas = []model.Quota{quota}
+	subscription.Usages = []model.Usage{usage}
+	return subscription, &subscription.Quotas[0]
+}
+
+func TestForecastResource(t *testing.T) {
+	now := day(10)
+
+	tests := []struct {
+		name                       string
+		quota                      float64
+		usage                      float64
+		dailyRate                  float64
+		end                        time.Time
+		periodEnd                  *time.Time
+		expectedRemaining          float64
+		expectedDaysRemaining      *float64
+		expectedExhaustedBeforeEnd bool
+	}{
+		{
+			name:                       "exhausted before the end of the subscription",
+			quota:                      100,
+			usage:                      40,
+			dailyRate:                  10,
+			end:                        day(30),
+			expectedRemaining:          60,
+			expectedDaysRemaining:      float64Pointer(6),
+			expectedExhaustedBeforeEnd: true,
+		},
+		{
+			name:                  "exhausted after the end of the subscription",
+			quota:                 100,
+			usage:                 40,
+			dailyRate:             1,
+			end:                   day(30),
+			expectedRemaining:     60,
+			expectedDaysRemaining: float64Pointer(60),
+		},
+		{
+			name:              "zero rate",
+			quota:             100,
+			usage:             40,
+			dailyRate:         0,
+			end:               day(30),
+			expectedRemaining: 60,
+		},
+		{
+			name:                       "exhausted quota",
+			quota:                      100,
+			usage:                      120,
+			dailyRate:                  0,
+			end:                        day(30),
+			expectedRemaining:          0,
+			expectedDaysRemaining:      float64Pointer(0),
+			expectedExhaustedBeforeEnd: true,
+		},
+		{
+			name:              "beyond the maximum number of forecast days",
+			quota:             MaxForecastDays + 1,
+			usage:             0,
+			dailyRate:         1,
+			end:               day(30),
+			expectedRemaining: MaxForecastDays + 1,
+		},
+		{
+			name:                  "exhausted after the end of the allotment period",
+			quota:                 100,
+			usage:                 40,
+			dailyRate:             10,
+			end:                   day(365),
+			periodEnd:             timePointer(day(12)),
+			expectedRemaining:     60,
+			expectedDaysRemaining: float64Pointer(6),
+		},
+		{
+			name:                       "exhausted before the end of the allotment period",
+			quota:                      100,
+			usage:                      40,
+			dailyRate:                  10,
+			end:                        day(365),
+			periodEnd:                  timePointer(day(20)),
+			expectedRemaining:          60,
+			expectedDaysRemaining:      float64Pointer(6),
+			expectedExhaustedBeforeEnd: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subscription, quota := forecastSubscription(test.quota, test.usage, test.end)
+			if test.periodEnd != nil {
+				subscription.AllotmentMode = true
+				subscription.CurrentPeriodEnd = test.periodEnd
+			}
+
+			actual := ForecastResource(subscription, quota, test.dailyRate, now)
+			if actual.Remaining != test.expectedRemaining {
+				t.Errorf("expected %g remaining, got %g", test.expectedRemaining, actual.Remaining)
+			}
+			if actual.ExhaustedBeforeEnd != test.expectedExhaustedBeforeEnd {
+				t.Errorf("expected exhausted before end to be %t", test.expectedExhaustedBeforeEnd)
+			}
+			if test.expectedDaysRemaining == nil {
+				if actual.DaysRemaining != nil || actual.ExhaustionDate != nil {
+					t.Errorf("expected no exhaustion date, got %v", actual.ExhaustionDate)
+				}
+				return
+			}
+			if actual.DaysRemaining == nil || actual.ExhaustionDate == nil {
+				t.Fatal("expected an exhaustion date")
+			}
+			if *actual.DaysRemaining != *test.expectedDaysRemaining {
+				t.Errorf("expected %g days remaining, got %g", *test.expectedDaysRemaining, *actual.DaysRemaining)
+			}
+			expectedExhaustionDate := now.Add(time.Duration(*test.expectedDaysRemaining * 24 * float64(time.Hour)))
+			if !actual.ExhaustionDate.Equal(expectedExhaustionDate) {
+				t.Errorf("expected exhaustion date %s, got %s", expectedExhaustionDate, *actual.ExhaustionDate)
+			}
+		})
+	}
+}
+
+func float64Pointer(v float64) *float64 {
+	return &v
+}
+
+func timePointer(t time.Time) *time.Time {
+	return &t
+}
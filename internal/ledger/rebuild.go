@@ -0,0 +1,198 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// DriftTolerance is the largest difference between a recorded usage value and a replayed usage value that isn't
+// reported as drift. A small tolerance is required because floating point addition isn't associative.
+var DriftTolerance = 1e-6
+
+// UsageKey identifies a usage value within a subscription.
+type UsageKey struct {
+	SubscriptionID string
+	ResourceTypeID string
+}
+
+// attributeUpdate returns the subscription that was active at the effective date of the given update. If multiple
+// subscriptions were active at that time then the one with the most recent effective start date is used. This function
+// assumes that the subscriptions are sorted in ascending order by effective start date.
+func attributeUpdate(subscriptions []*model.Subscription, update *model.Update) *model.Subscription {
+	for i := len(subscriptions) - 1; i >= 0; i-- {
+		if subscriptions[i].IsActiveAt(update.EffectiveDate) {
+			return subscriptions[i]
+		}
+	}
+	return nil
+}
+
+// ReplayUsages reconstructs the usage values for each subscription and resource type by applying the recorded usage
+// updates in order of their effective dates. Each update is attributed to the subscription that was active at its
+// effective date. Updates that can't be attributed to any subscription are returned separately. This function assumes
+// that the subscriptions are sorted in ascending order by effective start date. Be careful to ensure that the update
+// operation has been loaded for each update before calling this function.
+func ReplayUsages(subscriptions []*model.Subscription, updates []model.Update) (map[UsageKey]float64, []model.Update) {
+	updates = append([]model.Update(nil), updates...)
+	sort.SliceStable(updates, func(i, j int) bool {
+		return updates[i].EffectiveDate.Before(updates[j].EffectiveDate)
+	})
+
+	values := make(map[UsageKey]float64)
+	var unattributed []model.Update
+	for i := range updates {
+		update := &updates[i]
+		subscription := attributeUpdate(subscriptions, update)
+		if subscription == nil {
+			unattributed = append(unattributed, *update)
+			continue
+		}
+		key := UsageKey{SubscriptionID: *subscription.ID, ResourceTypeID: *update.ResourceTypeID}
+		values[key] = update.GetNewValue(update.UpdateOperation, values[key])
+	}
+
+	return values, unattributed
+}
+
+// usageRebuilder rebuilds usage values from the recorded usage updates.
+type usageRebuilder struct {
+	apply             bool
+	resourceTypeNames map[string]string
+	report            *model.UsageRebuildReport
+}
+
+// rebuildUserUsages compares the recorded usage values for a single user to the values obtained by replaying the
+// user's usage updates, adding any drift to the report. The recorded usage values are replaced if we're supposed to
+// apply the replayed values.
+func (r *usageRebuilder) rebuildUserUsages(ctx context.Context, tx *gorm.DB, username string) error {
+	wrapMsg := fmt.Sprintf("unable to rebuild the usages for user '%s'", username)
+	var err error
+
+	// Load the subscriptions and the usage updates.
+	subscriptions, err := db.ListSubscriptionUsagesForUser(ctx, tx, username)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+	updates, err := db.ListUsageUpdatesForUser(ctx, tx, username, nil, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	// Replay the usage updates.
+	replayed, unattributed := ReplayUsages(subscriptions, updates)
+	r.report.UsersChecked++
+	r.report.UnattributedUpdates += len(unattributed)
+
+	// Compare the recorded usage values to the replayed usage values. Usage values that have no updates should be zero.
+	var drift []model.UsageDrift
+	for _, subscription := range subscriptions {
+		recorded := make(map[string]float64)
+		for _, usage := range subscription.Usages {
+			recorded[*usage.ResourceTypeID] = usage.Usage
+		}
+		resourceTypeIDs := make(map[string]bool)
+		for resourceTypeID := range recorded {
+			resourceTypeIDs[resourceTypeID] = true
+		}
+		for key := range replayed {
+			if key.SubscriptionID == *subscription.ID {
+				resourceTypeIDs[key.ResourceTypeID] = true
+			}
+		}
+
+		for resourceTypeID := range resourceTypeIDs {
+			r.report.UsagesChecked++
+			key := UsageKey{SubscriptionID: *subscription.ID, ResourceTypeID: resourceTypeID}
+			difference := replayed[key] - recorded[resourceTypeID]
+			if math.Abs(difference) <= DriftTolerance {
+				continue
+			}
+			drift = append(drift, model.UsageDrift{
+				Username:       username,
+				SubscriptionID: key.SubscriptionID,
+				ResourceType:   r.resourceTypeNames[resourceTypeID],
+				RecordedUsage:  recorded[resourceTypeID],
+				ReplayedUsage:  replayed[key],
+				Difference:     difference,
+			})
+
+			// Replace the recorded usage value if we're supposed to.
+			if r.apply {
+				subscriptionID, resourceTypeID := key.SubscriptionID, key.ResourceTypeID
+				usage := model.Usage{
+					Usage:          replayed[key],
+					SubscriptionID: &subscriptionID,
+					ResourceTypeID: &resourceTypeID,
+				}
+				err = db.UpsertUsage(ctx, tx, &usage)
+				if err != nil {
+					return errors.Wrap(err, wrapMsg)
+				}
+			}
+		}
+	}
+
+	// Sort the drift so that the report is stable.
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].SubscriptionID != drift[j].SubscriptionID {
+			return drift[i].SubscriptionID < drift[j].SubscriptionID
+		}
+		return drift[i].ResourceType < drift[j].ResourceType
+	})
+	r.report.Drift = append(r.report.Drift, drift...)
+
+	return nil
+}
+
+// RebuildUsages replays the recorded usage updates for a user, or for every user if the username is empty, and reports
+// any recorded usage values that don't agree with the replayed values. The recorded usage values are replaced with the
+// replayed values if apply is true. Each user's usage values are rebuilt in a separate transaction.
+func RebuildUsages(
+	ctx context.Context, gormdb *gorm.DB, username string, apply bool,
+) (*model.UsageRebuildReport, error) {
+	wrapMsg := "unable to rebuild usages"
+	var err error
+
+	// Look up the resource type names so that they can be included in the report.
+	resourceTypes, err := db.ListResourceTypes(ctx, gormdb)
+	if err != nil {
+		return nil, errors.Wrap(err, wrapMsg)
+	}
+	resourceTypeNames := make(map[string]string)
+	for _, resourceType := range resourceTypes.ResourceTypes {
+		resourceTypeNames[*resourceType.ID] = resourceType.Name
+	}
+
+	// Determine which users to check.
+	usernames := []string{username}
+	if username == "" {
+		usernames, err = db.ListUsernames(ctx, gormdb)
+		if err != nil {
+			return nil, errors.Wrap(err, wrapMsg)
+		}
+	}
+
+	// Rebuild the usage values for each user.
+	rebuilder := &usageRebuilder{
+		apply:             apply,
+		resourceTypeNames: resourceTypeNames,
+		report:            &model.UsageRebuildReport{Applied: apply, Drift: make([]model.UsageDrift, 0)},
+	}
+	for _, username := range usernames {
+		err = gormdb.Transaction(func(tx *gorm.DB) error {
+			return rebuilder.rebuildUserUsages(ctx, tx, username)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rebuilder.report, nil
+}
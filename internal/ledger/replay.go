@@ -0,0 +1,48 @@
+package ledger
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+)
+
+// Replay reconstructs the value of a tracked quantity at the end of each bucket by applying the recorded updates in
+// order of their effective dates. The value is reset to zero at each of the reset times, which normally correspond to
+// the start dates of subscriptions, because each subscription starts with its own usage values. An update that takes
+// effect at the same time as a reset is applied after the reset. Be careful to ensure that the update operation has
+// been loaded for each update before calling this function.
+func Replay(updates []model.Update, resets []time.Time, buckets []Bucket) []float64 {
+	// Sort the updates and resets so that they can be merged as they're applied.
+	updates = append([]model.Update(nil), updates...)
+	sort.SliceStable(updates, func(i, j int) bool {
+		return updates[i].EffectiveDate.Before(updates[j].EffectiveDate)
+	})
+	resets = append([]time.Time(nil), resets...)
+	sort.Slice(resets, func(i, j int) bool {
+		return resets[i].Before(resets[j])
+	})
+
+	// Replay the updates, recording the value at the end of each bucket.
+	var value float64
+	var u, r int
+	values := make([]float64, len(buckets))
+	for i, bucket := range buckets {
+		for {
+			resetPending := r < len(resets) && resets[r].Before(bucket.End)
+			updatePending := u < len(updates) && updates[u].EffectiveDate.Before(bucket.End)
+			if resetPending && (!updatePending || !updates[u].EffectiveDate.Before(resets[r])) {
+				value = 0
+				r++
+			} else if updatePending {
+				value = updates[u].GetNewValue(updates[u].UpdateOperation, value)
+				u++
+			} else {
+				break
+			}
+		}
+		values[i] = value
+	}
+
+	return values
+}
@@ -0,0 +1,216 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+)
+
+var testStart = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// day returns the time at the start of the given number of days after the start of the tests.
+func day(n int) time.Time {
+	return testStart.AddDate(0, 0, n)
+}
+
+// testUpdate returns a usage update with the given operation, value, and effective date.
+func testUpdate(operation string, value float64, effectiveDate time.Time) model.Update {
+	resourceTypeID := "cpu"
+	return model.Update{
+		Value:           value,
+		EffectiveDate:   effectiveDate,
+		UpdateOperation: &model.UpdateOperation{Name: operation},
+		ResourceTypeID:  &resourceTypeID,
+	}
+}
+
+// dailyBuckets returns a bucket for each of the given number of days after the start of the tests.
+func dailyBuckets(t *testing.T, days int) []Bucket {
+	buckets, err := Buckets(day(0), day(days), IntervalDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buckets
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name     string
+		updates  []model.Update
+		resets   []time.Time
+		days     int
+		expected []float64
+	}{
+		{
+			name: "adds",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 5, day(0)),
+				testUpdate(model.UpdateOperationAdd, 3, day(2)),
+			},
+			days:     3,
+			expected: []float64{5, 5, 8},
+		},
+		{
+			name: "updates out of order",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationSet, 10, day(1)),
+				testUpdate(model.UpdateOperationAdd, 5, day(0)),
+			},
+			days:     2,
+			expected: []float64{5, 10},
+		},
+		{
+			name: "reset and update at the same time",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 5, day(0)),
+				testUpdate(model.UpdateOperationAdd, 3, day(1)),
+			},
+			resets:   []time.Time{day(1)},
+			days:     2,
+			expected: []float64{5, 3},
+		},
+		{
+			name: "subtract clamped at zero",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 4, day(0)),
+				testUpdate(model.UpdateOperationSubtract, 10, day(1)),
+				testUpdate(model.UpdateOperationAdd, 2, day(2)),
+			},
+			days:     3,
+			expected: []float64{4, 0, 2},
+		},
+		{
+			name: "reverse of a clamped subtract",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 4, day(0)),
+				testUpdate(model.UpdateOperationSubtract, 10, day(1)),
+				testUpdate(model.UpdateOperationReverse, -4, day(2)),
+			},
+			days:     3,
+			expected: []float64{4, 0, 4},
+		},
+		{
+			name: "set carried over at the start of a new subscription",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 7, day(0)),
+				testUpdate(model.UpdateOperationSet, 7, day(2)),
+				testUpdate(model.UpdateOperationAdd, 1, day(3)),
+			},
+			resets:   []time.Time{day(2)},
+			days:     4,
+			expected: []float64{7, 7, 7, 8},
+		},
+		{
+			name: "allotment reset",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 5, day(0)),
+				testUpdate(model.UpdateOperationSet, 0, day(1)),
+				testUpdate(model.UpdateOperationAdd, 2, day(1)),
+			},
+			days:     2,
+			expected: []float64{5, 2},
+		},
+		{
+			name:     "no updates",
+			resets:   []time.Time{day(1)},
+			days:     2,
+			expected: []float64{0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := Replay(test.updates, test.resets, dailyBuckets(t, test.days))
+			if len(actual) != len(test.expected) {
+				t.Fatalf("expected %d values, got %d", len(test.expected), len(actual))
+			}
+			for i := range actual {
+				if actual[i] != test.expected[i] {
+					t.Errorf("bucket %d: expected %g, got %g", i, test.expected[i], actual[i])
+				}
+			}
+		})
+	}
+}
+
+// testSubscription returns a subscription with the given identifier and effective dates.
+func testSubscription(id string, start, end time.Time) *model.Subscription {
+	return &model.Subscription{ID: &id, EffectiveStartDate: &start, EffectiveEndDate: &end}
+}
+
+func TestReplayUsages(t *testing.T) {
+	subscriptions := []*model.Subscription{
+		testSubscription("first", day(0), day(10)),
+		testSubscription("second", day(10), day(20)),
+	}
+
+	tests := []struct {
+		name                 string
+		updates              []model.Update
+		expected             map[string]float64
+		expectedUnattributed int
+	}{
+		{
+			name: "set carried over at the start of a new subscription",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 7, day(1)),
+				testUpdate(model.UpdateOperationSet, 7, day(10)),
+				testUpdate(model.UpdateOperationAdd, 1, day(11)),
+			},
+			expected: map[string]float64{"first": 7, "second": 8},
+		},
+		{
+			name: "update at the start of the first subscription",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 3, day(0)),
+			},
+			expected: map[string]float64{"first": 3},
+		},
+		{
+			name: "allotment reset",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 5, day(1)),
+				testUpdate(model.UpdateOperationSet, 0, day(5)),
+				testUpdate(model.UpdateOperationAdd, 2, day(6)),
+			},
+			expected: map[string]float64{"first": 2},
+		},
+		{
+			name: "reverse of a clamped subtract",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 4, day(1)),
+				testUpdate(model.UpdateOperationSubtract, 10, day(2)),
+				testUpdate(model.UpdateOperationReverse, -4, day(3)),
+			},
+			expected: map[string]float64{"first": 4},
+		},
+		{
+			name: "updates outside of every subscription",
+			updates: []model.Update{
+				testUpdate(model.UpdateOperationAdd, 3, day(-1)),
+				testUpdate(model.UpdateOperationAdd, 4, day(21)),
+			},
+			expected:             map[string]float64{},
+			expectedUnattributed: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, unattributed := ReplayUsages(subscriptions, test.updates)
+			if len(unattributed) != test.expectedUnattributed {
+				t.Errorf("expected %d unattributed updates, got %d", test.expectedUnattributed, len(unattributed))
+			}
+			if len(actual) != len(test.expected) {
+				t.Errorf("expected %d usage values, got %d", len(test.expected), len(actual))
+			}
+			for subscriptionID, expected := range test.expected {
+				key := UsageKey{SubscriptionID: subscriptionID, ResourceTypeID: "cpu"}
+				if actual[key] != expected {
+					t.Errorf("subscription %s: expected %g, got %g", subscriptionID, expected, actual[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Addon represents a product that can be purchased to increase a single quota in an existing subscription.
+//
+// swagger:model
+type Addon struct {
+	// The addon identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The addon name
+	Name string `gorm:"not null" json:"name,omitempty"`
+
+	// A brief description of the addon
+	Description string `gorm:"not null" json:"description,omitempty"`
+
+	// The resource type ID
+	ResourceTypeID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type whose quota is increased by the addon
+	ResourceType ResourceType `json:"resource_type,omitempty"`
+
+	// The amount that the quota is increased by default when the addon is applied
+	DefaultAmount float64 `gorm:"not null" json:"default_amount"`
+
+	// True if the addon is paid for by default when it's applied to a subscription
+	DefaultPaid bool `gorm:"not null" json:"default_paid"`
+
+	// The rates associated with the addon
+	AddonRates []AddonRate `json:"addon_rates,omitempty"`
+}
+
+// GetActiveAddonRate returns the currently active rate for an addon. The active addon rate is the rate with the most
+// recent effective timestamp that occurs at or before the current time. This function assumes that the addon rates are
+// sorted in ascending order by effective date.
+func (a *Addon) GetActiveAddonRate() (*AddonRate, error) {
+	currentTime := time.Now()
+
+	// Find the active addon rate.
+	var activeAddonRate *AddonRate
+	for i := range a.AddonRates {
+		if a.AddonRates[i].EffectiveDate.After(currentTime) {
+			break
+		}
+		activeAddonRate = &a.AddonRates[i]
+	}
+
+	// It's an error for an addon not to have an active rate.
+	if activeAddonRate == nil {
+		return nil, fmt.Errorf("no active rate found for addon %s", *a.ID)
+	}
+
+	return activeAddonRate, nil
+}
+
+// AddonRate represents the price of an addon as of a specific date.
+//
+// swagger:model
+type AddonRate struct {
+	// The addon rate identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v1()" json:"id,omitempty"`
+
+	// The addon ID
+	AddonID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The date that the addon rate becomes effective
+	EffectiveDate time.Time `json:"effective_date,omitempty"`
+
+	// The rate
+	Rate float64 `gorm:"type:decimal(10,2)" json:"rate"`
+}
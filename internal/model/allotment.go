@@ -0,0 +1,106 @@
+package model
+
+import "time"
+
+// SubscriptionAllotment represents the amount of a consumable resource that a subscription in allotment mode receives
+// at the beginning of each period.
+//
+// swagger:model
+type SubscriptionAllotment struct {
+	// The subscription allotment identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The subscription identifier
+	SubscriptionID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type identifier
+	ResourceTypeID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type associated with the allotment
+	ResourceType *ResourceType `json:"resource_type,omitempty"`
+
+	// The amount of the resource allotted in each period
+	Amount float64 `gorm:"not null" json:"amount"`
+}
+
+// SubscriptionPeriod records the final quota and usage values for a consumable resource in a completed period of a
+// subscription in allotment mode.
+//
+// swagger:model
+type SubscriptionPeriod struct {
+	// The subscription period identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The subscription identifier
+	SubscriptionID *string `gorm:"type:uuid;not null" json:"subscription_id,omitempty"`
+
+	// The resource type identifier
+	ResourceTypeID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type associated with the period
+	ResourceType *ResourceType `json:"resource_type,omitempty"`
+
+	// The date and time the period began
+	PeriodStart time.Time `gorm:"not null" json:"period_start"`
+
+	// The date and time the period ended
+	PeriodEnd time.Time `gorm:"not null" json:"period_end"`
+
+	// The quota at the end of the period
+	Quota float64 `gorm:"not null" json:"quota"`
+
+	// The usage at the end of the period
+	Usage float64 `gorm:"not null" json:"usage"`
+
+	// The date and time the period was recorded
+	//
+	// readOnly: true
+	CreatedAt *time.Time `gorm:"->" json:"created_at,omitempty"`
+}
+
+// AllotmentPeriodEnd returns the end of the allotment period that contains the given time. Allotment periods begin at
+// the effective start date of the subscription and at each yearly anniversary of it, and the final period ends at the
+// effective end date of the subscription.
+func (up *Subscription) AllotmentPeriodEnd(t time.Time) time.Time {
+	start := *up.EffectiveStartDate
+
+	// Find the first anniversary after the given time.
+	years := t.Year() - start.Year()
+	if years < 0 {
+		years = 0
+	}
+	periodEnd := start.AddDate(years, 0, 0)
+	for !periodEnd.After(t) {
+		years++
+		periodEnd = start.AddDate(years, 0, 0)
+	}
+
+	// The final period ends when the subscription does.
+	if up.EffectiveEndDate != nil && up.EffectiveEndDate.Before(periodEnd) {
+		return *up.EffectiveEndDate
+	}
+	return periodEnd
+}
+
+// GetCurrentPeriodEnd returns the date and time at which the current quotas and usages in the subscription stop
+// applying. This is the end of the current allotment period for subscriptions in allotment mode, and the effective end
+// date of the subscription otherwise.
+func (up *Subscription) GetCurrentPeriodEnd() *time.Time {
+	if up.AllotmentMode && up.CurrentPeriodEnd != nil {
+		return up.CurrentPeriodEnd
+	}
+	return up.EffectiveEndDate
+}
+
+// IsDueForRollover returns true if the subscription is in allotment mode and its current allotment period has ended,
+// but the subscription itself has not.
+func (up *Subscription) IsDueForRollover(now time.Time) bool {
+	if !up.AllotmentMode || up.CurrentPeriodEnd == nil || up.CurrentPeriodEnd.After(now) {
+		return false
+	}
+	return up.EffectiveEndDate == nil || up.CurrentPeriodEnd.Before(*up.EffectiveEndDate)
+}
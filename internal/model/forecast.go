@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// ResourceForecast describes the projected consumption of a single consumable resource within a subscription.
+//
+// swagger:model
+type ResourceForecast struct {
+	// The name of the resource type
+	ResourceType string `json:"resource_type"`
+
+	// The unit of measure used for the resource type
+	Unit string `json:"unit"`
+
+	// The resource usage limit in the active subscription
+	Quota float64 `json:"quota"`
+
+	// The current usage amount in the active subscription
+	Usage float64 `json:"usage"`
+
+	// The amount that may still be consumed before the quota is reached
+	Remaining float64 `json:"remaining"`
+
+	// The average amount consumed per day during the forecast window
+	DailyRate float64 `json:"daily_rate"`
+
+	// The projected date and time at which the usage reaches the quota. This field is omitted if nothing was consumed
+	// during the forecast window, or if the quota wouldn't be reached for more than a century.
+	ExhaustionDate *time.Time `json:"exhaustion_date,omitempty"`
+
+	// The number of days until the usage reaches the quota. This field is omitted whenever the exhaustion date is
+	// omitted.
+	DaysRemaining *float64 `json:"days_remaining,omitempty"`
+
+	// True if the usage is projected to reach the quota before the subscription ends, or before the current allotment
+	// period ends for subscriptions in allotment mode
+	ExhaustedBeforeEnd bool `json:"exhausted_before_end"`
+}
+
+// UsageForecast describes the projected consumption of each consumable resource in a user's active subscription.
+//
+// swagger:model
+type UsageForecast struct {
+	// The username
+	Username string `json:"username"`
+
+	// The subscription identifier
+	SubscriptionID string `json:"subscription_id"`
+
+	// The date and time the subscription expires
+	EffectiveEndDate *time.Time `json:"effective_end_date,omitempty"`
+
+	// The start of the time range used to compute consumption rates
+	WindowStart time.Time `json:"window_start"`
+
+	// The end of the time range used to compute consumption rates
+	WindowEnd time.Time `json:"window_end"`
+
+	// The forecasts for each consumable resource
+	Resources []ResourceForecast `json:"resources"`
+}
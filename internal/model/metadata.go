@@ -0,0 +1,98 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidMetadata is returned when metadata isn't a JSON object.
+var ErrInvalidMetadata = errors.New("metadata must be a JSON object")
+
+// Metadata represents the structured metadata recorded with an update. It's stored in a JSONB column so that updates
+// can be found by metadata key and value.
+//
+// swagger:model
+type Metadata map[string]any
+
+// decodeMetadata decodes a JSON object into metadata. Numbers are decoded as json.Number rather than float64 so that
+// large numeric identifiers are preserved exactly. An error is returned if the encoded value contains anything after
+// the JSON object.
+func decodeMetadata(encoded []byte, m *Metadata) error {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(m); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the metadata")
+	}
+	return nil
+}
+
+// ParseMetadata parses metadata supplied in a request. The metadata may be either a JSON object or a string containing
+// an encoded JSON object. Null values and empty strings are treated as empty metadata, in which case nil is returned.
+func ParseMetadata(raw json.RawMessage) (Metadata, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	// Accept JSON objects that have been encoded as strings.
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		if encoded == "" {
+			return nil, nil
+		}
+		raw = json.RawMessage(encoded)
+	}
+
+	// Anything other than a JSON object is rejected.
+	var metadata Metadata
+	if err := decodeMetadata(raw, &metadata); err != nil || metadata == nil {
+		return nil, ErrInvalidMetadata
+	}
+
+	return metadata, nil
+}
+
+// With returns a copy of the metadata with the given key set to the given value.
+func (m Metadata) With(key string, value any) Metadata {
+	result := make(Metadata, len(m)+1)
+	for k, v := range m {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
+
+// Value converts metadata to a value that can be stored in the database.
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// Scan converts a value obtained from the database to metadata.
+func (m *Metadata) Scan(value any) error {
+	var encoded []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		encoded = v
+	case string:
+		encoded = []byte(v)
+	default:
+		return fmt.Errorf("unsupported metadata type: %T", value)
+	}
+	return decodeMetadata(encoded, m)
+}
@@ -0,0 +1,151 @@
+package model
+
+import "time"
+
+// The roles that a member may have in an organization.
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Organization represents a group of users who share the resources provided by the organization's subscription.
+//
+// swagger:model
+type Organization struct {
+	// The organization identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The organization name
+	Name string `gorm:"not null;unique" json:"name"`
+
+	// A brief description of the organization
+	Description string `gorm:"not null" json:"description"`
+
+	// The members of the organization
+	Members []OrganizationMember `json:"members,omitempty"`
+
+	// The date and time the organization was created
+	//
+	// readOnly: true
+	CreatedAt *time.Time `gorm:"->" json:"created_at,omitempty"`
+
+	// The date and time the organization was last modified
+	//
+	// readOnly: true
+	LastModifiedAt *time.Time `gorm:"->" json:"last_modified_at,omitempty"`
+}
+
+// OrganizationMember represents a user's membership in an organization.
+//
+// swagger:model
+type OrganizationMember struct {
+	// The organization member identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The organization identifier
+	OrganizationID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The organization
+	Organization *Organization `json:"organization,omitempty"`
+
+	// The user identifier
+	UserID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The user
+	User *User `json:"user,omitempty"`
+
+	// The member's role in the organization: owner, admin, or member
+	Role string `gorm:"not null;default:member" json:"role"`
+
+	// Limits on the amount of each resource that the member may consume from the organization's pool
+	Caps []OrganizationMemberCap `json:"caps,omitempty"`
+
+	// The date and time the user joined the organization
+	//
+	// readOnly: true
+	CreatedAt *time.Time `gorm:"->" json:"created_at,omitempty"`
+}
+
+// GetCap returns the limit on the amount of the resource type with the given ID that the member may consume, or nil if
+// the member's consumption of the resource type isn't limited. Be careful to ensure that the member's caps have been
+// loaded before calling this function.
+func (m *OrganizationMember) GetCap(resourceTypeID string) *float64 {
+	for i := range m.Caps {
+		if *m.Caps[i].ResourceTypeID == resourceTypeID {
+			return &m.Caps[i].Cap
+		}
+	}
+	return nil
+}
+
+// OrganizationMemberCap represents a limit on the amount of a resource that a member may consume from an
+// organization's pool.
+//
+// swagger:model
+type OrganizationMemberCap struct {
+	// The organization member cap identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The organization member identifier
+	OrganizationMemberID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type identifier
+	ResourceTypeID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type
+	ResourceType *ResourceType `json:"resource_type,omitempty"`
+
+	// The maximum amount of the resource that the member may consume
+	Cap float64 `gorm:"not null" json:"cap"`
+}
+
+// OrganizationMemberUsage represents the amount of a resource that a member has consumed from an organization's pool.
+//
+// swagger:model
+type OrganizationMemberUsage struct {
+	// The organization member usage identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The identifier of the organization's subscription
+	SubscriptionID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The user identifier
+	UserID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The user
+	User *User `json:"user,omitempty"`
+
+	// The resource type identifier
+	ResourceTypeID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type
+	ResourceType *ResourceType `json:"resource_type,omitempty"`
+
+	// The usage amount
+	Usage float64 `gorm:"not null" json:"usage"`
+
+	// The date and time the usage amount was last modified
+	//
+	// readOnly: true
+	LastModifiedAt *time.Time `gorm:"->" json:"last_modified_at,omitempty"`
+}
+
+// OrganizationSubscription represents an organization's subscription along with the amount of each resource that each
+// member has consumed from it.
+//
+// swagger:model
+type OrganizationSubscription struct {
+	Subscription
+
+	// The amount of each resource that each member has consumed
+	MemberUsages []*OrganizationMemberUsage `json:"member_usages"`
+}
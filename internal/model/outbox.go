@@ -0,0 +1,114 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox event type constants.
+const (
+	OutboxEventTypeQuotaThreshold = "quota.threshold"
+)
+
+// QuotaThresholds lists the percentages of a quota that trigger a quota threshold event when usage crosses them.
+var QuotaThresholds = []float64{80, 90, 100}
+
+// OutboxEvent represents an event that was recorded in the same transaction as the change that caused it, and that
+// needs to be delivered to other services.
+type OutboxEvent struct {
+	// The event identifier
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The type of the event
+	EventType string `gorm:"not null" json:"event_type"`
+
+	// The JSON-encoded event payload
+	Payload string `gorm:"type:jsonb;not null" json:"-"`
+
+	// The number of failed attempts to deliver the event
+	Attempts int `gorm:"not null;default:0" json:"-"`
+
+	// The error message from the most recent failed attempt to deliver the event
+	LastError *string `json:"-"`
+
+	// The date and time the event was recorded
+	CreatedAt *time.Time `gorm:"->" json:"created_at,omitempty"`
+
+	// The date and time the event was delivered
+	DispatchedAt *time.Time `json:"-"`
+
+	// The date and time at which the dispatcher's claim on the event expires, if the event has been claimed
+	ClaimedUntil *time.Time `json:"-"`
+}
+
+// MarshalJSON encodes an outbox event for delivery, embedding the payload as JSON rather than as a string.
+func (e OutboxEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID        *string         `json:"id,omitempty"`
+		EventType string          `json:"event_type"`
+		Payload   json.RawMessage `json:"payload"`
+		CreatedAt *time.Time      `json:"created_at,omitempty"`
+	}{
+		ID:        e.ID,
+		EventType: e.EventType,
+		Payload:   json.RawMessage(e.Payload),
+		CreatedAt: e.CreatedAt,
+	})
+}
+
+// QuotaThresholdEvent is the payload of an event that is recorded when a usage update pushes the usage for a resource
+// type past one of the quota thresholds.
+type QuotaThresholdEvent struct {
+	// The username of the subscription owner, or of the member whose usage caused the event for subscriptions owned by
+	// an organization
+	Username string `json:"username"`
+
+	// The name of the organization that owns the subscription, for subscriptions owned by an organization
+	Organization string `json:"organization,omitempty"`
+
+	// The subscription identifier
+	SubscriptionID string `json:"subscription_id"`
+
+	// The name of the resource type
+	ResourceType string `json:"resource_type"`
+
+	// The unit of measure used for the resource type
+	Unit string `json:"unit"`
+
+	// The threshold that was crossed, expressed as a percentage of the quota
+	Threshold float64 `json:"threshold"`
+
+	// The resource usage limit
+	Quota float64 `json:"quota"`
+
+	// The usage amount before the update
+	PreviousUsage float64 `json:"previous_usage"`
+
+	// The usage amount after the update
+	Usage float64 `json:"usage"`
+
+	// The usage amount after the update, expressed as a percentage of the quota
+	UsagePercentage float64 `json:"usage_percentage"`
+}
+
+// GetCrossedQuotaThreshold returns the highest quota threshold crossed when the usage changes from the previous usage
+// amount to the new usage amount. The second return value is false if no threshold was crossed. Thresholds can't be
+// crossed if the quota isn't positive.
+func GetCrossedQuotaThreshold(quota, previousUsage, usage float64) (float64, bool) {
+	var crossed float64
+	var found bool
+
+	if quota <= 0 {
+		return crossed, found
+	}
+
+	for _, threshold := range QuotaThresholds {
+		limit := quota * threshold / 100
+		if previousUsage < limit && usage >= limit {
+			crossed = threshold
+			found = true
+		}
+	}
+
+	return crossed, found
+}
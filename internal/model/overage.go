@@ -0,0 +1,80 @@
+package model
+
+// Overage describes a resource type for which the usage is at or above the quota in a subscription.
+//
+// swagger:model
+type Overage struct {
+	// The resource type
+	ResourceType ResourceType `json:"resource_type"`
+
+	// The resource usage limit
+	Quota float64 `json:"quota"`
+
+	// The current usage amount
+	Usage float64 `json:"usage"`
+
+	// The amount by which the usage exceeds the quota
+	Overage float64 `json:"overage"`
+}
+
+// GetOverages returns the list of resource types for which the current usage is at or above the quota. Resource types
+// with no usage are never listed, even if the quota is zero. Be careful to ensure that all user plan details have been
+// loaded before calling this function.
+func (up *Subscription) GetOverages() []Overage {
+	result := make([]Overage, 0)
+	for _, quota := range up.Quotas {
+		usageValue := up.GetCurrentUsageValue(*quota.ResourceTypeID)
+		if usageValue > 0 && usageValue >= quota.Quota {
+			result = append(result, Overage{
+				ResourceType: quota.ResourceType,
+				Quota:        quota.Quota,
+				Usage:        usageValue,
+				Overage:      usageValue - quota.Quota,
+			})
+		}
+	}
+	return result
+}
+
+// SubscriptionOverage describes an overage for a single resource type in an active subscription.
+//
+// swagger:model
+type SubscriptionOverage struct {
+	// The subscription identifier
+	SubscriptionID string `json:"subscription_id"`
+
+	// The username of the subscriber
+	Username string `json:"username"`
+
+	// The name of the subscription plan
+	PlanName string `json:"plan_name"`
+
+	// The resource type name
+	ResourceTypeName string `json:"resource_type_name"`
+
+	// The unit of measure used for the resource type
+	ResourceTypeUnit string `json:"resource_type_unit"`
+
+	// The resource usage limit
+	Quota float64 `json:"quota"`
+
+	// The current usage amount
+	Usage float64 `json:"usage"`
+
+	// The amount by which the usage exceeds the quota
+	Overage float64 `json:"overage"`
+
+	// The overage as a percentage of the quota; omitted if the quota is zero
+	OveragePercentage *float64 `json:"overage_percentage,omitempty"`
+}
+
+// SubscriptionOverageListing represents a list of overages in active subscriptions.
+//
+// swagger:model
+type SubscriptionOverageListing struct {
+	// The overages in the listing
+	Overages []*SubscriptionOverage `json:"overages"`
+
+	// The total number of matched overages
+	Total int64 `json:"total"`
+}
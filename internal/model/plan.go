@@ -27,6 +27,10 @@ type Plan struct {
 
 	// The rates associated with the plan.
 	PlanRates []PlanRate `json:"plan_rates,omitempty"`
+
+	// True if the plan has been retired. Existing subscriptions remain on archived plans, but new subscriptions to
+	// archived plans can't be created.
+	Archived bool `gorm:"not null;default:false" json:"archived"`
 }
 
 // Returns the currently active rate for a subscription plan. The active plan rate is the plan with the most recent
@@ -144,12 +148,18 @@ type Subscription struct {
 	// The date and time the subscription expires
 	EffectiveEndDate *time.Time `gorm:"" json:"effective_end_date,omitempty"`
 
-	// The user identifier
-	UserID *string `gorm:"type:uuid;not null" json:"-"`
+	// The user identifier, for subscriptions owned by a user
+	UserID *string `gorm:"type:uuid" json:"-"`
 
-	// The user associated with the subscription
+	// The user associated with the subscription, for subscriptions owned by a user
 	User *User `json:"user,omitempty"`
 
+	// The organization identifier, for subscriptions owned by an organization
+	OrganizationID *string `gorm:"type:uuid" json:"-"`
+
+	// The organization associated with the subscription, for subscriptions owned by an organization
+	Organization *Organization `json:"organization,omitempty"`
+
 	// The identifier of the plan associated with the subscription
 	PlanID *string `gorm:"type:uuid;not null" json:"-"`
 
@@ -170,6 +180,21 @@ type Subscription struct {
 
 	// The plan rate at the time the subscription was created.
 	PlanRate *PlanRate `json:"plan_rate,omitempty"`
+
+	// The addons that have been applied to the subscription
+	SubscriptionAddons []SubscriptionAddon `json:"subscription_addons"`
+
+	// True if consumable quotas and usages reset at each yearly anniversary of the effective start date
+	AllotmentMode bool `gorm:"not null;default:false" json:"allotment_mode"`
+
+	// The date and time the current allotment period began, for subscriptions in allotment mode
+	CurrentPeriodStart *time.Time `json:"current_period_start,omitempty"`
+
+	// The date and time the current allotment period ends, for subscriptions in allotment mode
+	CurrentPeriodEnd *time.Time `json:"current_period_end,omitempty"`
+
+	// The amount of each consumable resource allotted in each period, for subscriptions in allotment mode
+	Allotments []SubscriptionAllotment `json:"allotments,omitempty"`
 }
 
 // GetCurrentUsageValue returns the current usage value for the resource type with the given resource type ID. Be
@@ -183,3 +208,33 @@ func (up *Subscription) GetCurrentUsageValue(resourceTypeID string) float64 {
 	}
 	return usageValue
 }
+
+// GetCurrentQuotaValue returns the current quota value for the resource type with the given resource type ID. Be
+// careful to ensure that all user plan details have been loaded before calling this function.
+func (up *Subscription) GetCurrentQuotaValue(resourceTypeID string) float64 {
+	var quotaValue float64
+	for _, quota := range up.Quotas {
+		if *quota.ResourceTypeID == resourceTypeID {
+			quotaValue = quota.Quota
+		}
+	}
+	return quotaValue
+}
+
+// IsActiveAt determines whether or not the subscription is active at the given time. A subscription is active from its
+// effective start date through its effective end date, inclusive. A subscription without an effective end date is
+// active at any time from its effective start date onward.
+func (up *Subscription) IsActiveAt(t time.Time) bool {
+	if up.EffectiveStartDate == nil || t.Before(*up.EffectiveStartDate) {
+		return false
+	}
+	return up.EffectiveEndDate == nil || !t.After(*up.EffectiveEndDate)
+}
+
+// UpdateQuotaLimits computes the soft limit, hard limit, and state of each quota in the subscription based on the
+// current usage. Be careful to ensure that all user plan details have been loaded before calling this function.
+func (up *Subscription) UpdateQuotaLimits() {
+	for i := range up.Quotas {
+		up.Quotas[i].UpdateLimits(up.GetCurrentUsageValue(*up.Quotas[i].ResourceTypeID))
+	}
+}
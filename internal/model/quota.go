@@ -2,6 +2,14 @@ package model
 
 import "time"
 
+// The possible states of a quota, based on the current usage.
+const (
+	QuotaStateOK       = "ok"
+	QuotaStateWarning  = "warning"
+	QuotaStateGrace    = "grace"
+	QuotaStateExceeded = "exceeded"
+)
+
 // Quota represents a resource usage limit associated with a subscription.
 //
 // swagger:model
@@ -25,9 +33,51 @@ type Quota struct {
 
 	// The date and time the quota was last modified
 	LastModifiedAt *time.Time `gorm:"->" json:"last_modified_at,omitempty"`
+
+	// The usage amount at which the user is warned that the quota is being approached
+	//
+	// readOnly: true
+	SoftLimit float64 `gorm:"-" json:"soft_limit"`
+
+	// The usage amount at which the user is blocked from consuming more of the resource
+	//
+	// readOnly: true
+	HardLimit float64 `gorm:"-" json:"hard_limit"`
+
+	// The state of the quota based on the current usage: ok, warning, grace, or exceeded
+	//
+	// readOnly: true
+	State string `gorm:"-" json:"state,omitempty"`
 }
 
 // TableName specifies the table name to use the database.
 func (q *Quota) TableName() string {
 	return "quotas"
 }
+
+// GetQuotaState determines the state of a quota for the given usage amount. The state is "ok" if the usage is below the
+// soft limit, "warning" if the usage is at or above the soft limit but below the quota, "grace" if the usage is at or
+// above the quota but below the hard limit, and "exceeded" if the usage is at or above the hard limit. A resource with
+// no usage is always "ok", even if the quota is zero.
+func GetQuotaState(resourceType *ResourceType, quota, usage float64) string {
+	switch {
+	case usage <= 0:
+		return QuotaStateOK
+	case usage >= resourceType.GetHardLimit(quota):
+		return QuotaStateExceeded
+	case usage >= quota:
+		return QuotaStateGrace
+	case usage >= resourceType.GetSoftLimit(quota):
+		return QuotaStateWarning
+	default:
+		return QuotaStateOK
+	}
+}
+
+// UpdateLimits computes the soft limit, hard limit, and state of the quota for the given usage amount. Be careful to
+// ensure that the resource type has been loaded before calling this function.
+func (q *Quota) UpdateLimits(usage float64) {
+	q.SoftLimit = q.ResourceType.GetSoftLimit(q.Quota)
+	q.HardLimit = q.ResourceType.GetHardLimit(q.Quota)
+	q.State = GetQuotaState(&q.ResourceType, q.Quota, usage)
+}
@@ -0,0 +1,147 @@
+package model
+
+import "fmt"
+
+// QuotaCheckResult describes the decision made when a caller asks whether a user may consume a resource.
+//
+// swagger:model
+type QuotaCheckResult struct {
+	// True if the requested amount may be consumed
+	Allowed bool `json:"allowed"`
+
+	// The name of the resource type
+	ResourceType string `json:"resource_type"`
+
+	// The requested amount
+	Requested float64 `json:"requested"`
+
+	// The resource usage limit in the active subscription
+	Quota float64 `json:"quota"`
+
+	// The current usage amount in the active subscription
+	Usage float64 `json:"usage"`
+
+	// The amount held by outstanding reservations in the active subscription
+	Held float64 `json:"held"`
+
+	// The usage amount at which the user is warned that the quota is being approached
+	SoftLimit float64 `json:"soft_limit"`
+
+	// The usage amount at which the user is blocked from consuming more of the resource
+	HardLimit float64 `json:"hard_limit"`
+
+	// The amount that may still be consumed before the hard limit is reached
+	Remaining float64 `json:"remaining"`
+
+	// The current state of the quota: ok, warning, grace, or exceeded
+	State string `json:"state,omitempty"`
+
+	// The state that the quota would be in if the requested amount were consumed
+	ProjectedState string `json:"projected_state,omitempty"`
+
+	// A brief explanation of the decision
+	Reason string `json:"reason"`
+
+	// The name of the organization whose pool the resource is consumed from, if the user belongs to one
+	Organization string `json:"organization,omitempty"`
+
+	// The maximum amount of the resource that the member may consume from the organization's pool, if there is one
+	MemberCap *float64 `json:"member_cap,omitempty"`
+
+	// The amount of the resource that the member has consumed from the organization's pool
+	MemberUsage *float64 `json:"member_usage,omitempty"`
+
+	// The amount held by the member's outstanding reservations against the organization's pool
+	MemberHeld *float64 `json:"member_held,omitempty"`
+}
+
+// ApplyMemberCap further limits the result of a quota check for a resource consumed from an organization's pool by the
+// amount that the member may still consume before reaching the cap.
+func (r *QuotaCheckResult) ApplyMemberCap(resourceType *ResourceType, cap, memberUsage, memberHeld float64) {
+	r.MemberCap = &cap
+	r.MemberUsage = &memberUsage
+	r.MemberHeld = &memberHeld
+
+	// The member may only consume up to the cap.
+	memberRemaining := cap - memberUsage - memberHeld
+	if memberRemaining < 0 {
+		memberRemaining = 0
+	}
+	if memberRemaining >= r.Remaining {
+		return
+	}
+	r.Remaining = memberRemaining
+
+	// Deny the request if it exceeds the member's remaining allowance.
+	if r.Requested > memberRemaining {
+		r.Allowed = false
+		r.Reason = fmt.Sprintf(
+			"the requested amount, %g %s, exceeds the member's remaining allowance of %g %s",
+			r.Requested, resourceType.Unit, memberRemaining, resourceType.Unit,
+		)
+	}
+}
+
+// GetQuota returns the quota for the resource type with the given resource type ID, or nil if the subscription doesn't
+// have a quota for the resource type. Be careful to ensure that all user plan details have been loaded before calling
+// this function.
+func (up *Subscription) GetQuota(resourceTypeID string) *Quota {
+	for i := range up.Quotas {
+		if *up.Quotas[i].ResourceTypeID == resourceTypeID {
+			return &up.Quotas[i]
+		}
+	}
+	return nil
+}
+
+// CheckQuota determines whether or not the given amount of a resource may be consumed within the subscription. The held
+// amount is the total amount of the resource held by outstanding reservations, which is not available for consumption.
+// Be careful to ensure that all user plan details have been loaded before calling this function.
+func (up *Subscription) CheckQuota(resourceType *ResourceType, amount, held float64) *QuotaCheckResult {
+	result := &QuotaCheckResult{
+		ResourceType: resourceType.Name,
+		Requested:    amount,
+	}
+
+	// The request has to be denied if there's no quota for the resource type.
+	quota := up.GetQuota(*resourceType.ID)
+	if quota == nil {
+		result.Reason = fmt.Sprintf("the active subscription has no quota for %s", resourceType.Name)
+		return result
+	}
+
+	// Determine the limits and the remaining allowance. Users may consume resources up to the hard limit.
+	result.Quota = quota.Quota
+	result.Usage = up.GetCurrentUsageValue(*resourceType.ID)
+	result.Held = held
+	result.SoftLimit = resourceType.GetSoftLimit(result.Quota)
+	result.HardLimit = resourceType.GetHardLimit(result.Quota)
+	result.Remaining = result.HardLimit - result.Usage - result.Held
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+
+	// Determine the current and projected quota states.
+	result.State = GetQuotaState(resourceType, result.Quota, result.Usage)
+	result.ProjectedState = GetQuotaState(resourceType, result.Quota, result.Usage+result.Held+amount)
+
+	// Make the decision.
+	if amount <= result.Remaining {
+		result.Allowed = true
+		switch result.ProjectedState {
+		case QuotaStateGrace, QuotaStateExceeded:
+			result.Reason = "the requested amount is within the grace allowance above the quota"
+		case QuotaStateWarning:
+			result.Reason = "the requested amount is within the remaining allowance, but the quota is nearly reached"
+		default:
+			result.Reason = "the requested amount is within the remaining allowance"
+		}
+	} else {
+		result.Reason = fmt.Sprintf(
+			"the requested amount, %g %s, exceeds the remaining allowance of %g %s",
+			amount, resourceType.Unit, result.Remaining, resourceType.Unit,
+		)
+	}
+
+	return result
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// The possible states of a quota override.
+const (
+	QuotaOverrideStatusPending = "pending"
+	QuotaOverrideStatusActive  = "active"
+	QuotaOverrideStatusRetired = "retired"
+)
+
+// QuotaOverride represents a temporary increase to a quota in a subscription. The quota is raised by the override
+// amount when the override becomes active, and lowered by the same amount when the override is retired.
+//
+// swagger:model
+type QuotaOverride struct {
+	// The quota override identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The identifier of the subscription containing the quota
+	SubscriptionID *string `gorm:"type:uuid;not null" json:"subscription_id,omitempty"`
+
+	// The resource type identifier
+	ResourceTypeID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type associated with the quota override
+	ResourceType *ResourceType `json:"resource_type,omitempty"`
+
+	// The amount by which the quota is increased
+	Amount float64 `gorm:"not null" json:"amount"`
+
+	// The reason for the quota override
+	Reason string `gorm:"not null" json:"reason"`
+
+	// The current status of the quota override: pending, active, or retired
+	Status string `gorm:"not null;default:pending" json:"status"`
+
+	// The date and time the quota override becomes active
+	EffectiveStartDate time.Time `gorm:"not null" json:"effective_start_date"`
+
+	// The date and time the quota override expires
+	EffectiveEndDate time.Time `gorm:"not null" json:"effective_end_date"`
+
+	// The person or service that requested the quota override, if known
+	Actor *string `json:"actor,omitempty"`
+
+	// The date and time the quota override was retired
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+
+	// The date and time the quota override was created
+	//
+	// readOnly: true
+	CreatedAt *time.Time `gorm:"->" json:"created_at,omitempty"`
+
+	// The date and time the quota override was last modified
+	//
+	// readOnly: true
+	LastModifiedAt *time.Time `gorm:"->" json:"last_modified_at,omitempty"`
+}
+
+// IsDueForActivation returns true if the quota override is waiting to become active and its start date has passed.
+func (o *QuotaOverride) IsDueForActivation(now time.Time) bool {
+	return o.Status == QuotaOverrideStatusPending && !o.EffectiveStartDate.After(now)
+}
+
+// IsDueForRetirement returns true if the quota override hasn't been retired yet and its end date has passed.
+func (o *QuotaOverride) IsDueForRetirement(now time.Time) bool {
+	return o.Status != QuotaOverrideStatusRetired && !o.EffectiveEndDate.After(now)
+}
@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// The possible states of a reservation.
+const (
+	ReservationStatusHeld      = "held"
+	ReservationStatusCommitted = "committed"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+)
+
+// Reservation represents a hold placed on part of the remaining allowance for a resource type in a subscription.
+//
+// swagger:model
+type Reservation struct {
+	// The reservation identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The identifier of the subscription that the hold was placed against
+	SubscriptionID *string `gorm:"type:uuid;not null" json:"subscription_id,omitempty"`
+
+	// The identifier of the user who placed the hold
+	UserID *string `gorm:"type:uuid" json:"-"`
+
+	// The resource type identifier
+	ResourceTypeID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The resource type associated with the reservation
+	ResourceType *ResourceType `json:"resource_type,omitempty"`
+
+	// The amount that was reserved
+	Amount float64 `gorm:"not null" json:"amount"`
+
+	// The amount that was actually consumed, which is only set once the reservation has been committed
+	CommittedAmount *float64 `json:"committed_amount,omitempty"`
+
+	// The current status of the reservation: held, committed, released, or expired
+	Status string `gorm:"not null;default:held" json:"status"`
+
+	// The date and time when the hold expires if it hasn't been committed or released
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+
+	// The date and time the reservation was created
+	//
+	// readOnly: true
+	CreatedAt *time.Time `gorm:"->" json:"created_at,omitempty"`
+
+	// The date and time the reservation was last modified
+	//
+	// readOnly: true
+	LastModifiedAt *time.Time `gorm:"->" json:"last_modified_at,omitempty"`
+}
+
+// IsOutstanding returns true if the reservation is still holding part of the remaining allowance as of the given time.
+func (r *Reservation) IsOutstanding(now time.Time) bool {
+	return r.Status == ReservationStatusHeld && r.ExpiresAt.After(now)
+}
+
+// IsOpen returns true if the reservation may still be committed or released. Expired holds remain open because the
+// resource was most likely consumed anyway; only the hold on the remaining allowance has lapsed.
+func (r *Reservation) IsOpen() bool {
+	return r.Status == ReservationStatusHeld || r.Status == ReservationStatusExpired
+}
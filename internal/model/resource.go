@@ -7,6 +7,12 @@ const (
 	RESOURCE_TYPE_DATA_SIZE = "data.size"
 )
 
+// The default soft and hard limit settings for resource types.
+const (
+	DefaultWarningPercentage = 90
+	DefaultGracePercentage   = 0
+)
+
 // ResourceTypeList represents a list of resource types.
 //
 // swagger:model
@@ -50,4 +56,40 @@ type ResourceType struct {
 	// so they would be considered consumable. Conversely, data storage can be reclaimed by removing files form the
 	// data store, so data storage is not considered to be consumable.
 	Consumable bool `json:"consumable"`
+
+	// The percentage of the quota at which users are warned that they're approaching the quota; defaults to 90
+	WarningPercentage float64 `gorm:"not null;default:90" json:"warning_percentage"`
+
+	// The percentage of the quota by which users may exceed the quota before they're blocked; defaults to 0
+	GracePercentage float64 `gorm:"not null;default:0" json:"grace_percentage"`
+}
+
+// NewResourceType returns a resource type with the default soft and hard limit settings. Request bodies should be
+// decoded into the returned resource type so that the default settings are used when they're not specified.
+func NewResourceType() ResourceType {
+	return ResourceType{
+		WarningPercentage: DefaultWarningPercentage,
+		GracePercentage:   DefaultGracePercentage,
+	}
+}
+
+// ValidateLimits verifies that the soft and hard limit settings for a resource type are valid.
+func (rt *ResourceType) ValidateLimits() error {
+	if rt.WarningPercentage <= 0 || rt.WarningPercentage > 100 {
+		return fmt.Errorf("the warning percentage must be greater than 0 and no greater than 100")
+	}
+	if rt.GracePercentage < 0 {
+		return fmt.Errorf("the grace percentage must not be less than 0")
+	}
+	return nil
+}
+
+// GetSoftLimit returns the usage amount at which users are warned that they're approaching the given quota.
+func (rt *ResourceType) GetSoftLimit(quota float64) float64 {
+	return quota * rt.WarningPercentage / 100
+}
+
+// GetHardLimit returns the usage amount at which users are blocked from consuming more of the resource.
+func (rt *ResourceType) GetHardLimit(quota float64) float64 {
+	return quota * (1 + rt.GracePercentage/100)
 }
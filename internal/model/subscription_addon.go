@@ -0,0 +1,56 @@
+package model
+
+// SubscriptionAddon represents an addon that has been applied to a subscription.
+//
+// swagger:model
+type SubscriptionAddon struct {
+	// The subscription addon identifier
+	//
+	// readOnly: true
+	ID *string `gorm:"type:uuid;default:uuid_generate_v4()" json:"id,omitempty"`
+
+	// The subscription ID
+	SubscriptionID *string `gorm:"type:uuid;not null" json:"subscription_id,omitempty"`
+
+	// The addon ID
+	AddonID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The addon that was applied to the subscription
+	Addon *Addon `json:"addon,omitempty"`
+
+	// The amount that the quota was increased by when the addon was applied
+	Amount float64 `gorm:"not null" json:"amount"`
+
+	// True if the user paid for the addon
+	Paid bool `gorm:"not null" json:"paid"`
+
+	// The ID of the addon rate at the time the addon was applied
+	AddonRateID *string `gorm:"type:uuid;not null" json:"-"`
+
+	// The addon rate at the time the addon was applied
+	AddonRate *AddonRate `json:"addon_rate,omitempty"`
+}
+
+// GetRefundAmount returns the amount that should be refunded to the user if the addon is removed from the
+// subscription. Only paid addons are eligible for refunds, and the refund amount is the rate that was in effect when
+// the addon was applied. Be careful to ensure that the addon rate has been loaded before calling this function.
+func (sa *SubscriptionAddon) GetRefundAmount() float64 {
+	if !sa.Paid || sa.AddonRate == nil {
+		return 0
+	}
+	return sa.AddonRate.Rate
+}
+
+// SubscriptionAddonRemoval describes an addon that was removed from a subscription.
+//
+// swagger:model
+type SubscriptionAddonRemoval struct {
+	// The subscription addon that was removed
+	SubscriptionAddon SubscriptionAddon `json:"subscription_addon"`
+
+	// The amount that should be refunded to the user
+	RefundAmount float64 `json:"refund_amount"`
+
+	// The quota value after the addon was removed
+	Quota float64 `json:"quota"`
+}
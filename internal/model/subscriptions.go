@@ -21,6 +21,9 @@ type SubscriptionOptions struct {
 
 	// The effective end date of the subscription.
 	EndDate *timestamp.Timestamp `json:"end_date"`
+
+	// True if consumable quotas should be allotted once per year rather than all at once.
+	AllotmentMode *bool `json:"allotment_mode"`
 }
 
 // Return the appropriate paid flag for the subscription options.
@@ -32,6 +35,15 @@ func (o *SubscriptionOptions) IsPaid() bool {
 	}
 }
 
+// Return the appropriate allotment mode flag for the subscription options.
+func (o *SubscriptionOptions) IsAllotmentMode() bool {
+	if o.AllotmentMode == nil {
+		return false
+	} else {
+		return *o.AllotmentMode
+	}
+}
+
 // Return the number of periods for the subscription options.
 func (o *SubscriptionOptions) GetPeriods() int32 {
 	if o.Periods == nil {
@@ -1,6 +1,7 @@
 package timestamp
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -75,3 +76,8 @@ func (t *Timestamp) UnmarshalJSON(data []byte) error {
 	*t, err = Parse(value)
 	return err
 }
+
+// MarshalJSON converts a timestamp to JSON.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t))
+}
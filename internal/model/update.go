@@ -1,6 +1,7 @@
 package model
 
 import (
+	"math"
 	"time"
 )
 
@@ -10,6 +11,14 @@ const (
 	ValueTypeUsages = "usages"
 )
 
+// Update operation name constants.
+const (
+	UpdateOperationSet      = "SET"
+	UpdateOperationAdd      = "ADD"
+	UpdateOperationSubtract = "SUBTRACT"
+	UpdateOperationReverse  = "REVERSE"
+)
+
 // UpdateOperation defines the structure of an available update operation in the qms database.
 //
 // swagger:model
@@ -25,14 +34,64 @@ type UpdateOperation struct {
 }
 
 type Update struct {
-	ID                *string      `gorm:"type:uuid;default:uuid_generate_v1()" json:"id"`
-	ValueType         string       `json:"value_type"`
-	Value             float64      `gorm:"not null" json:"value"`
-	EffectiveDate     time.Time    `gorm:"type:date;not null" json:"effective_date"`
-	UpdateOperationID *string      `gorm:"type:uuid;not null" json:"-"`
-	ResourceTypeID    *string      `gorm:"type:uuid;not null" json:"-"`
-	ResourceType      ResourceType `json:"resource_types"`
-	UserID            *string      `gorm:"type:uuid" json:"-"`
-	User              User         `json:"user"`
-	Metadata          *string      `json:"metadata"`
+	ID                *string          `gorm:"type:uuid;default:uuid_generate_v1()" json:"id"`
+	ValueType         string           `json:"value_type"`
+	Value             float64          `gorm:"not null" json:"value"`
+	EffectiveDate     time.Time        `gorm:"type:date;not null" json:"effective_date"`
+	UpdateOperationID *string          `gorm:"type:uuid;not null" json:"-"`
+	UpdateOperation   *UpdateOperation `json:"update_operation,omitempty"`
+	ResourceTypeID    *string          `gorm:"type:uuid;not null" json:"-"`
+	ResourceType      ResourceType     `json:"resource_types"`
+	UserID            *string          `gorm:"type:uuid" json:"-"`
+	User              User             `json:"user"`
+	Metadata          Metadata         `gorm:"type:jsonb" json:"metadata"`
+	IdempotencyKey    *string          `json:"idempotency_key,omitempty"`
+	RequestHash       *string          `json:"-"`
+	OriginalUpdateID  *string          `gorm:"type:uuid" json:"original_update_id,omitempty"`
+	PreviousValue     *float64         `json:"previous_value,omitempty"`
+	NewValue          *float64         `json:"new_value,omitempty"`
+	Actor             *string          `json:"actor,omitempty"`
+	BatchID           *string          `gorm:"type:uuid" json:"batch_id,omitempty"`
+	OrganizationID    *string          `gorm:"type:uuid" json:"organization_id,omitempty"`
+}
+
+// GetChange returns the amount by which the update changed the tracked value. The second return value is false if the
+// change can't be determined, which is the case for updates other than ADD that were recorded before previous values
+// were tracked. Be careful to ensure that the update operation has been loaded before calling this function.
+func (u *Update) GetChange(operation *UpdateOperation) (float64, bool) {
+	switch {
+	case u.PreviousValue != nil:
+		return u.GetNewValue(operation, *u.PreviousValue) - *u.PreviousValue, true
+	case operation.Name == UpdateOperationAdd:
+		return u.Value, true
+	default:
+		return 0, false
+	}
+}
+
+// GetNewValue returns the tracked value after the update is applied to the given current value. Values that are
+// decreased by an update are never allowed to drop below zero. Be careful to ensure that the update operation has been
+// loaded before calling this function.
+func (u *Update) GetNewValue(operation *UpdateOperation, currentValue float64) float64 {
+	switch operation.Name {
+	case UpdateOperationSet:
+		return u.Value
+	case UpdateOperationAdd:
+		return currentValue + u.Value
+	case UpdateOperationSubtract, UpdateOperationReverse:
+		return math.Max(currentValue-u.Value, 0)
+	default:
+		return currentValue
+	}
+}
+
+// UpdateListing represents a list of updates.
+//
+// swagger:model
+type UpdateListing struct {
+	// The updates in the listing
+	Updates []*Update `json:"updates"`
+
+	// The total number of matched updates
+	Total int64 `json:"total"`
 }
@@ -0,0 +1,87 @@
+package model
+
+import "testing"
+
+func float64Pointer(v float64) *float64 {
+	return &v
+}
+
+func TestUpdateGetNewValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		value     float64
+		current   float64
+		expected  float64
+	}{
+		{"set", UpdateOperationSet, 7, 3, 7},
+		{"set to zero", UpdateOperationSet, 0, 3, 0},
+		{"add", UpdateOperationAdd, 4, 3, 7},
+		{"subtract", UpdateOperationSubtract, 2, 3, 1},
+		{"subtract clamped at zero", UpdateOperationSubtract, 10, 4, 0},
+		{"reverse an add", UpdateOperationReverse, 4, 7, 3},
+		{"reverse a clamped subtract", UpdateOperationReverse, -4, 0, 4},
+		{"unknown operation", "BOGUS", 4, 3, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			update := &Update{Value: test.value}
+			actual := update.GetNewValue(&UpdateOperation{Name: test.operation}, test.current)
+			if actual != test.expected {
+				t.Errorf("expected %g, got %g", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUpdateGetChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		operation     string
+		value         float64
+		previousValue *float64
+		expected      float64
+		expectedOK    bool
+	}{
+		{"add without a previous value", UpdateOperationAdd, 4, nil, 4, true},
+		{"add with a previous value", UpdateOperationAdd, 4, float64Pointer(3), 4, true},
+		{"set with a previous value", UpdateOperationSet, 2, float64Pointer(5), -3, true},
+		{"set without a previous value", UpdateOperationSet, 2, nil, 0, false},
+		{"subtract", UpdateOperationSubtract, 2, float64Pointer(5), -2, true},
+		{"subtract clamped at zero", UpdateOperationSubtract, 10, float64Pointer(4), -4, true},
+		{"subtract without a previous value", UpdateOperationSubtract, 2, nil, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			update := &Update{Value: test.value, PreviousValue: test.previousValue}
+			actual, ok := update.GetChange(&UpdateOperation{Name: test.operation})
+			if ok != test.expectedOK {
+				t.Fatalf("expected ok to be %t, got %t", test.expectedOK, ok)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %g, got %g", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReversingAClampedSubtractRestoresTheUsage(t *testing.T) {
+	subtract := &Update{Value: 10, PreviousValue: float64Pointer(4)}
+	subtractOperation := &UpdateOperation{Name: UpdateOperationSubtract}
+	usage := subtract.GetNewValue(subtractOperation, *subtract.PreviousValue)
+	if usage != 0 {
+		t.Fatalf("expected the subtract to be clamped at 0, got %g", usage)
+	}
+
+	change, ok := subtract.GetChange(subtractOperation)
+	if !ok {
+		t.Fatal("expected the change made by the subtract to be known")
+	}
+	reverse := &Update{Value: change}
+	usage = reverse.GetNewValue(&UpdateOperation{Name: UpdateOperationReverse}, usage)
+	if usage != 4 {
+		t.Errorf("expected the reversal to restore the usage to 4, got %g", usage)
+	}
+}
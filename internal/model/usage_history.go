@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// UsageHistoryBucket represents the reconstructed usage value at the end of a single interval.
+//
+// swagger:model
+type UsageHistoryBucket struct {
+	// The start of the interval
+	Start time.Time `json:"start"`
+
+	// The end of the interval
+	End time.Time `json:"end"`
+
+	// The usage value at the end of the interval
+	Usage float64 `json:"usage"`
+}
+
+// ResourceTypeUsageHistory represents the usage history for a single resource type.
+//
+// swagger:model
+type ResourceTypeUsageHistory struct {
+	// The resource type
+	ResourceType ResourceType `json:"resource_type"`
+
+	// The usage values for each interval
+	Buckets []UsageHistoryBucket `json:"buckets"`
+}
+
+// UsageHistory represents the usage history for a user over a range of time.
+//
+// swagger:model
+type UsageHistory struct {
+	// The username
+	Username string `json:"username"`
+
+	// The interval used to divide the time range: day, week, or month
+	Interval string `json:"interval"`
+
+	// The usage history for each resource type
+	ResourceTypes []ResourceTypeUsageHistory `json:"resource_types"`
+}
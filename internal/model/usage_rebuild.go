@@ -0,0 +1,45 @@
+package model
+
+// UsageDrift describes a recorded usage value that doesn't agree with the value obtained by replaying the usage updates
+// that were recorded for the same subscription and resource type.
+//
+// swagger:model
+type UsageDrift struct {
+	// The username
+	Username string `json:"username"`
+
+	// The subscription identifier
+	SubscriptionID string `json:"subscription_id"`
+
+	// The name of the resource type
+	ResourceType string `json:"resource_type"`
+
+	// The usage value currently recorded in the usages table
+	RecordedUsage float64 `json:"recorded_usage"`
+
+	// The usage value obtained by replaying the usage updates
+	ReplayedUsage float64 `json:"replayed_usage"`
+
+	// The replayed usage value minus the recorded usage value
+	Difference float64 `json:"difference"`
+}
+
+// UsageRebuildReport summarizes the results of rebuilding usage values from the usage updates.
+//
+// swagger:model
+type UsageRebuildReport struct {
+	// True if the recorded usage values were replaced with the replayed usage values
+	Applied bool `json:"applied"`
+
+	// The number of users whose usage values were checked
+	UsersChecked int `json:"users_checked"`
+
+	// The number of usage values that were checked
+	UsagesChecked int `json:"usages_checked"`
+
+	// The number of usage updates that couldn't be attributed to any subscription
+	UnattributedUpdates int `json:"unattributed_updates"`
+
+	// The usage values that don't agree with the replayed usage values
+	Drift []UsageDrift `json:"drift"`
+}
@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// DefaultBatchSize is the default maximum number of events delivered in a single dispatcher run.
+var DefaultBatchSize = 100
+
+// DefaultMaxAttempts is the default number of failed delivery attempts after which an event is no longer retried.
+var DefaultMaxAttempts = 10
+
+// DefaultClaimDuration is the default amount of time that a dispatcher may spend delivering a batch of events before
+// other dispatchers may claim the undelivered events in the batch.
+var DefaultClaimDuration = 5 * time.Minute
+
+// Dispatcher delivers pending outbox events to a sink.
+type Dispatcher struct {
+	GORMDB        *gorm.DB
+	Sink          Sink
+	BatchSize     int
+	MaxAttempts   int
+	ClaimDuration time.Duration
+}
+
+// NewDispatcher creates a new dispatcher with the default batch size, maximum number of delivery attempts, and claim
+// duration.
+func NewDispatcher(gormdb *gorm.DB, sink Sink) *Dispatcher {
+	return &Dispatcher{
+		GORMDB:        gormdb,
+		Sink:          sink,
+		BatchSize:     DefaultBatchSize,
+		MaxAttempts:   DefaultMaxAttempts,
+		ClaimDuration: DefaultClaimDuration,
+	}
+}
+
+// claimPending claims a batch of pending events for delivery in a short transaction. The events may not be claimed by
+// other dispatchers until the returned time.
+func (d *Dispatcher) claimPending(ctx context.Context) ([]*model.OutboxEvent, time.Time, error) {
+	var events []*model.OutboxEvent
+	claimedUntil := time.Now().Add(d.ClaimDuration)
+
+	err := d.GORMDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+
+		// Look up and lock the pending events.
+		events, err = db.GetPendingOutboxEvents(ctx, tx, d.BatchSize, d.MaxAttempts)
+		if err != nil {
+			return err
+		}
+
+		// Claim the events.
+		eventIDs := make([]string, len(events))
+		for i, event := range events {
+			eventIDs[i] = *event.ID
+		}
+		return db.ClaimOutboxEvents(ctx, tx, eventIDs, claimedUntil)
+	})
+
+	return events, claimedUntil, err
+}
+
+// DispatchPending delivers a batch of pending events to the sink. The events are claimed in a short transaction so
+// that concurrent dispatchers don't deliver the same event twice, and then delivered without holding any database
+// locks. Any events that can't be delivered before the claim expires are left for a subsequent run. Delivery failures
+// are recorded on the event and retried on subsequent runs.
+func (d *Dispatcher) DispatchPending(ctx context.Context) error {
+	log := log.WithFields(logrus.Fields{"context": "dispatching outbox events"})
+
+	// Claim the pending events.
+	events, claimedUntil, err := d.claimPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Deliver each event.
+	for _, event := range events {
+		log := log.WithFields(logrus.Fields{"event_id": *event.ID, "event_type": event.EventType})
+
+		// Another dispatcher may claim the event once our claim expires.
+		if !time.Now().Before(claimedUntil) {
+			log.Warn("the claim on the remaining events expired before they could be delivered")
+			break
+		}
+
+		sendErr := d.Sink.Send(ctx, event)
+		if sendErr != nil {
+			log.Errorf("unable to deliver the event: %s", sendErr.Error())
+			err = db.MarkOutboxEventFailed(ctx, d.GORMDB, *event.ID, sendErr)
+		} else {
+			log.Debug("delivered the event")
+			err = db.MarkOutboxEventDispatched(ctx, d.GORMDB, *event.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+)
+
+// LogSink delivers outbox events by appending them to a file as JSON lines, or by writing them to the service log if
+// no file path is configured. This sink is primarily intended for local development.
+type LogSink struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewLogSink creates a new log sink. Events are written to the service log if the path is empty.
+func NewLogSink(path string) *LogSink {
+	return &LogSink{path: path}
+}
+
+// Send delivers a single event to the sink.
+func (s *LogSink) Send(_ context.Context, event *model.OutboxEvent) error {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode the outbox event")
+	}
+
+	// Write the event to the service log if no file path was configured.
+	if s.path == "" {
+		log.WithField("context", "log sink").Info(string(encoded))
+		return nil
+	}
+
+	// Append the event to the file.
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "unable to open %s", s.path)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(encoded, '\n'))
+	if err != nil {
+		return errors.Wrapf(err, "unable to write to %s", s.path)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/cyverse/qms/logging"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logging.GetLogger().WithFields(logrus.Fields{"package": "outbox"})
+
+// Sink type constants.
+const (
+	SinkTypeLog     = "log"
+	SinkTypeWebhook = "webhook"
+)
+
+// Sink represents a destination that outbox events can be delivered to.
+type Sink interface {
+	// Send delivers a single event to the sink. The event is considered to have been delivered if no error is
+	// returned.
+	Send(ctx context.Context, event *model.OutboxEvent) error
+}
+
+// SinkSettings contains the settings used to create a sink.
+type SinkSettings struct {
+	// The type of sink to create: log or webhook.
+	Type string
+
+	// The URL that events are posted to by the webhook sink.
+	WebhookURL string
+
+	// The path to the file that events are appended to by the log sink. Events are written to the service log if the
+	// path is empty.
+	LogPath string
+}
+
+// NewSink creates a new sink using the given settings.
+func NewSink(settings *SinkSettings) (Sink, error) {
+	switch settings.Type {
+	case SinkTypeLog:
+		return NewLogSink(settings.LogPath), nil
+	case SinkTypeWebhook:
+		if settings.WebhookURL == "" {
+			return nil, fmt.Errorf("a webhook URL is required for the webhook outbox sink")
+		}
+		return NewWebhookSink(settings.WebhookURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported outbox sink type: %s", settings.Type)
+	}
+}
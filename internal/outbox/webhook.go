@@ -0,0 +1,59 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+)
+
+// WebhookTimeout is the maximum amount of time to wait for a webhook request to complete.
+var WebhookTimeout = 30 * time.Second
+
+// WebhookSink delivers outbox events by posting them to an HTTP endpoint. Any 2xx response is treated as a successful
+// delivery.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a new webhook sink that posts events to the given URL.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: WebhookTimeout},
+	}
+}
+
+// Send delivers a single event to the sink.
+func (s *WebhookSink) Send(ctx context.Context, event *model.OutboxEvent) error {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode the outbox event")
+	}
+
+	// Build the request.
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(encoded))
+	if err != nil {
+		return errors.Wrap(err, "unable to build the webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send the request.
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "unable to send the webhook request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("the webhook request failed with status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -113,6 +113,38 @@ func ValidateIntQueryParam(ctx echo.Context, name string, defaultValue *int32, c
 	return result, nil
 }
 
+// ValidateFloatQueryParam extracts an optional floating point query parameter and validates it.
+func ValidateFloatQueryParam(ctx echo.Context, name string, defaultValue *float64, checks ...string) (float64, error) {
+	errMsg := fmt.Sprintf("invalid query parameter: %s", name)
+	value := ctx.QueryParam(name)
+	var result float64
+
+	// Assume that the parameter is required if there's no default.
+	if defaultValue == nil && value == "" {
+		return result, fmt.Errorf("missing required query parameter: %s", name)
+	}
+
+	// If no value was provided at this point then the parameter is optional; return the default value.
+	if value == "" {
+		return *defaultValue, nil
+	}
+
+	// Parse the parameter value.
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return result, errors.Wrap(err, errMsg)
+	}
+
+	// Perform any checks that we're supposed to perform.
+	for _, check := range checks {
+		if err = v.Var(result, check); err != nil {
+			return result, errors.Wrap(err, errMsg)
+		}
+	}
+
+	return result, nil
+}
+
 // contains returns true if the given slice of strings contains the given string.
 func contains(strs []string, str string) bool {
 	for _, s := range strs {
@@ -0,0 +1,198 @@
+package quotas
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// rolloverBatchSize is the maximum number of subscriptions rolled over in a single transaction.
+const rolloverBatchSize = 100
+
+// resetUsage sets the usage for a resource type in a subscription to zero and records the update in the database. The
+// amounts that each member has consumed are reset as well for subscriptions owned by an organization.
+func resetUsage(
+	ctx context.Context,
+	tx *gorm.DB,
+	subscription *model.Subscription,
+	resourceTypeID *string,
+	updateOperation *model.UpdateOperation,
+	opts *UpdateOptions,
+) error {
+	wrapMsg := "unable to reset the usage"
+
+	// Reset the usage.
+	currentUsageValue := subscription.GetCurrentUsageValue(*resourceTypeID)
+	usage := &model.Usage{
+		SubscriptionID: subscription.ID,
+		ResourceTypeID: resourceTypeID,
+		Usage:          0,
+	}
+	err := db.UpsertUsage(ctx, tx, usage)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+	if subscription.OrganizationID != nil {
+		err = db.ResetOrganizationMemberUsages(ctx, tx, *subscription.ID, *resourceTypeID)
+		if err != nil {
+			return errors.Wrap(err, wrapMsg)
+		}
+	}
+
+	// Record the update in the database.
+	newUsageValue := 0.0
+	update := model.Update{
+		ValueType:         model.ValueTypeUsages,
+		Value:             0,
+		EffectiveDate:     *opts.EffectiveDate,
+		UpdateOperationID: updateOperation.ID,
+		ResourceTypeID:    resourceTypeID,
+		UserID:            subscription.UserID,
+		OrganizationID:    subscription.OrganizationID,
+		Metadata:          opts.Metadata,
+		PreviousValue:     &currentUsageValue,
+		NewValue:          &newUsageValue,
+		Actor:             opts.Actor,
+	}
+	err = db.SaveUpdate(ctx, tx, &update)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// rolloverPeriod ends the current allotment period of a subscription and begins the next one. The final quota and usage
+// values for each allotted resource type are recorded in the period history, after which the usage is reset to zero.
+// The quota is left alone: it already holds one period's allotment, and resetting it would discard addons, active
+// quota overrides, and changes made by administrators during the subscription. Be careful to ensure that all of the
+// subscription details have been loaded before calling this function.
+func rolloverPeriod(
+	ctx context.Context, tx *gorm.DB, subscription *model.Subscription, updateOperation *model.UpdateOperation,
+) error {
+	wrapMsg := fmt.Sprintf("unable to roll over the allotment period of subscription '%s'", *subscription.ID)
+	var err error
+
+	// Determine the boundaries of the completed and next periods.
+	periodStart := *subscription.CurrentPeriodStart
+	periodEnd := *subscription.CurrentPeriodEnd
+	nextPeriodEnd := subscription.AllotmentPeriodEnd(periodEnd)
+
+	actor := SystemActor
+	opts := &UpdateOptions{
+		Metadata:      model.Metadata{"allotment_period_start": periodEnd.Format(time.RFC3339)},
+		Actor:         &actor,
+		EffectiveDate: &periodEnd,
+	}
+
+	for _, allotment := range subscription.Allotments {
+		resourceTypeID := allotment.ResourceTypeID
+
+		// Record the final values for the completed period.
+		period := &model.SubscriptionPeriod{
+			SubscriptionID: subscription.ID,
+			ResourceTypeID: resourceTypeID,
+			PeriodStart:    periodStart,
+			PeriodEnd:      periodEnd,
+			Quota:          subscription.GetCurrentQuotaValue(*resourceTypeID),
+			Usage:          subscription.GetCurrentUsageValue(*resourceTypeID),
+		}
+		err = db.SaveSubscriptionPeriod(ctx, tx, period)
+		if err != nil {
+			return errors.Wrap(err, wrapMsg)
+		}
+
+		// Reset the usage.
+		err = resetUsage(ctx, tx, subscription, resourceTypeID, updateOperation, opts)
+		if err != nil {
+			return errors.Wrap(err, wrapMsg)
+		}
+	}
+
+	// Begin the next period.
+	subscription.CurrentPeriodStart = &periodEnd
+	subscription.CurrentPeriodEnd = &nextPeriodEnd
+	return db.UpdateSubscriptionPeriod(ctx, tx, subscription)
+}
+
+// RolloverSubscription begins a new allotment period for a subscription in allotment mode for each period that has
+// ended as of the given time. The number of periods that were rolled over is returned.
+func RolloverSubscription(ctx context.Context, tx *gorm.DB, subscriptionID string, now time.Time) (int, error) {
+	var count int
+
+	// Look up the update operation used to reset the usages.
+	updateOperation, err := db.GetUpdateOperation(ctx, tx, model.UpdateOperationSet)
+	if err != nil {
+		return 0, err
+	} else if updateOperation == nil {
+		return 0, fmt.Errorf("update operation %s not found", model.UpdateOperationSet)
+	}
+
+	for {
+		// Load the subscription details, which change with each rollover.
+		subscription, err := db.GetSubscriptionDetails(ctx, tx, subscriptionID)
+		if err != nil {
+			return count, err
+		}
+		if !subscription.IsDueForRollover(now) {
+			return count, nil
+		}
+
+		// Roll over the current period.
+		err = rolloverPeriod(ctx, tx, subscription, updateOperation)
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// rolloverBatch rolls over the allotment periods of a single batch of subscriptions. The number of subscriptions in the
+// batch is returned along with the number of periods that were rolled over.
+func rolloverBatch(ctx context.Context, tx *gorm.DB, now time.Time) (int, int, error) {
+	var rolledOver int
+
+	subscriptions, err := db.ListSubscriptionsDueForRollover(ctx, tx, now, rolloverBatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, subscription := range subscriptions {
+		count, err := RolloverSubscription(ctx, tx, *subscription.ID, now)
+		if err != nil {
+			return 0, 0, err
+		}
+		rolledOver += count
+	}
+
+	return len(subscriptions), rolledOver, nil
+}
+
+// ProcessDueRollovers begins new allotment periods for subscriptions in allotment mode whose current periods have
+// ended. Subscriptions are processed in batches, each in its own transaction, so that a failure only rolls back the
+// batch being processed. The number of periods that were rolled over is returned.
+func ProcessDueRollovers(ctx context.Context, gormdb *gorm.DB, now time.Time) (int, error) {
+	var rolledOver int
+
+	for {
+		var count, batchRolledOver int
+		err := gormdb.Transaction(func(tx *gorm.DB) error {
+			var err error
+			count, batchRolledOver, err = rolloverBatch(ctx, tx, now)
+			return err
+		})
+		if err != nil {
+			return rolledOver, err
+		}
+
+		rolledOver += batchRolledOver
+		if count < rolloverBatchSize {
+			return rolledOver, nil
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package quotas
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// SystemActor is the actor recorded for quota updates that are made automatically rather than at someone's request.
+const SystemActor = "qms"
+
+// overrideBatchSize is the maximum number of quota overrides processed in a single transaction.
+const overrideBatchSize = 100
+
+// overrideMetadata returns the metadata to record with quota updates made for a quota override.
+func overrideMetadata(override *model.QuotaOverride) model.Metadata {
+	return model.Metadata{"quota_override_id": *override.ID, "reason": override.Reason}
+}
+
+// applyOverride adjusts the quota affected by a quota override using the update operation with the given name.
+func applyOverride(
+	ctx context.Context, tx *gorm.DB, override *model.QuotaOverride, operationName string, actor *string,
+) error {
+	wrapMsg := fmt.Sprintf("unable to apply quota override '%s'", *override.ID)
+
+	// Look up the update operation.
+	updateOperation, err := db.GetUpdateOperation(ctx, tx, operationName)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	} else if updateOperation == nil {
+		return fmt.Errorf("%s: update operation %s not found", wrapMsg, operationName)
+	}
+
+	// Load the subscription containing the quota.
+	subscription, err := db.GetSubscriptionDetails(ctx, tx, *override.SubscriptionID)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	// Update the quota.
+	opts := &UpdateOptions{Metadata: overrideMetadata(override), Actor: actor}
+	_, err = Update(ctx, tx, subscription, override.ResourceTypeID, updateOperation, override.Amount, opts)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	return nil
+}
+
+// ActivateOverride raises the quota affected by a pending quota override by the override amount and marks the override
+// as active.
+func ActivateOverride(ctx context.Context, tx *gorm.DB, override *model.QuotaOverride, actor *string) error {
+	if override.Status != model.QuotaOverrideStatusPending {
+		return fmt.Errorf("quota override '%s' can't be activated because it is %s", *override.ID, override.Status)
+	}
+
+	// Raise the quota.
+	err := applyOverride(ctx, tx, override, model.UpdateOperationAdd, actor)
+	if err != nil {
+		return err
+	}
+
+	// Mark the override as active.
+	override.Status = model.QuotaOverrideStatusActive
+	return db.UpdateQuotaOverrideStatus(ctx, tx, override)
+}
+
+// RetireOverride marks a quota override as retired. If the override is active then the quota that it affects is
+// lowered by the override amount first. Pending overrides never changed the quota, so they're simply marked as retired.
+func RetireOverride(
+	ctx context.Context, tx *gorm.DB, override *model.QuotaOverride, actor *string, now time.Time,
+) error {
+	switch override.Status {
+	case model.QuotaOverrideStatusActive:
+		err := applyOverride(ctx, tx, override, model.UpdateOperationSubtract, actor)
+		if err != nil {
+			return err
+		}
+	case model.QuotaOverrideStatusPending:
+	default:
+		return fmt.Errorf("quota override '%s' can't be retired because it is %s", *override.ID, override.Status)
+	}
+
+	// Mark the override as retired.
+	override.Status = model.QuotaOverrideStatusRetired
+	override.RetiredAt = &now
+	return db.UpdateQuotaOverrideStatus(ctx, tx, override)
+}
+
+// CarryOverOverrides moves the unretired quota overrides in a user's other subscriptions that overlap the effective
+// period of a new subscription into the new subscription. Each override is split at the new subscription's start date:
+// the part before that date stays with the original subscription, and the rest becomes a new override in the new
+// subscription, which is activated immediately if it's already due. Overrides in organization subscriptions aren't
+// carried over.
+func CarryOverOverrides(
+	ctx context.Context, tx *gorm.DB, subscription *model.Subscription, actor *string, now time.Time,
+) error {
+	wrapMsg := "unable to carry over the quota overrides from the previous subscriptions"
+
+	if subscription.UserID == nil {
+		return nil
+	}
+	startDate := *subscription.EffectiveStartDate
+	endDate := *subscription.EffectiveEndDate
+
+	overrides, err := db.ListUnretiredQuotaOverridesForUserID(ctx, tx, *subscription.UserID)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	for _, override := range overrides {
+		if *override.SubscriptionID == *subscription.ID {
+			continue
+		}
+		if !override.EffectiveEndDate.After(startDate) || !override.EffectiveStartDate.Before(endDate) {
+			continue
+		}
+
+		// Remove the overlapping part of the override from the original subscription.
+		replacementStartDate := override.EffectiveStartDate
+		replacementEndDate := override.EffectiveEndDate
+		if replacementStartDate.Before(startDate) {
+			replacementStartDate = startDate
+			override.EffectiveEndDate = startDate
+			err = db.UpdateQuotaOverrideEndDate(ctx, tx, override)
+			if err == nil && override.IsDueForRetirement(now) {
+				err = RetireOverride(ctx, tx, override, actor, now)
+			}
+		} else {
+			err = RetireOverride(ctx, tx, override, actor, now)
+		}
+		if err != nil {
+			return errors.Wrap(err, wrapMsg)
+		}
+
+		// Add the remainder of the override to the new subscription.
+		replacement := &model.QuotaOverride{
+			SubscriptionID:     subscription.ID,
+			ResourceTypeID:     override.ResourceTypeID,
+			Amount:             override.Amount,
+			Reason:             override.Reason,
+			Status:             model.QuotaOverrideStatusPending,
+			EffectiveStartDate: replacementStartDate,
+			EffectiveEndDate:   replacementEndDate,
+			Actor:              override.Actor,
+		}
+		err = db.SaveQuotaOverride(ctx, tx, replacement)
+		if err == nil && replacement.IsDueForActivation(now) {
+			err = ActivateOverride(ctx, tx, replacement, actor)
+		}
+		if err != nil {
+			return errors.Wrap(err, wrapMsg)
+		}
+	}
+
+	return nil
+}
+
+// processDueOverrideBatch activates or retires a single batch of quota overrides that are due as of the given time. The
+// number of overrides in the batch is returned along with the number of overrides activated and retired.
+func processDueOverrideBatch(ctx context.Context, tx *gorm.DB, now time.Time) (int, int, int, error) {
+	var activated, retired int
+	actor := SystemActor
+
+	overrides, err := db.ListDueQuotaOverrides(ctx, tx, now, overrideBatchSize)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	for _, override := range overrides {
+		switch {
+		case override.IsDueForRetirement(now):
+			err = RetireOverride(ctx, tx, override, &actor, now)
+			retired++
+		case override.IsDueForActivation(now):
+			err = ActivateOverride(ctx, tx, override, &actor)
+			activated++
+		}
+		if err != nil {
+			return 0, 0, 0, err
+		}
+	}
+
+	return len(overrides), activated, retired, nil
+}
+
+// ProcessDueOverrides activates pending quota overrides whose start dates have passed and retires quota overrides whose
+// end dates have passed. Overrides are processed in batches, each in its own transaction, so that a failure only rolls
+// back the batch being processed. The numbers of overrides activated and retired are returned.
+func ProcessDueOverrides(ctx context.Context, gormdb *gorm.DB, now time.Time) (int, int, error) {
+	var activated, retired int
+
+	for {
+		var count, batchActivated, batchRetired int
+		err := gormdb.Transaction(func(tx *gorm.DB) error {
+			var err error
+			count, batchActivated, batchRetired, err = processDueOverrideBatch(ctx, tx, now)
+			return err
+		})
+		if err != nil {
+			return activated, retired, err
+		}
+
+		activated += batchActivated
+		retired += batchRetired
+		if count < overrideBatchSize {
+			return activated, retired, nil
+		}
+	}
+}
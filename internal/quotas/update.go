@@ -0,0 +1,90 @@
+package quotas
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// UpdateOptions contains optional information to record with a quota update.
+type UpdateOptions struct {
+	// Metadata to record with the update.
+	Metadata model.Metadata
+
+	// The person or service that requested the update, if known.
+	Actor *string
+
+	// The identifier of the bulk operation that made the update, if any.
+	BatchID *string
+
+	// The date and time the update takes effect; defaults to the current time.
+	EffectiveDate *time.Time
+}
+
+// Update applies a quota update to a subscription and records the update, along with the previous and new quota values,
+// in the database. The new quota value is returned. Be careful to ensure that the quotas in the subscription have been
+// loaded before calling this function.
+func Update(
+	ctx context.Context,
+	tx *gorm.DB,
+	subscription *model.Subscription,
+	resourceTypeID *string,
+	updateOperation *model.UpdateOperation,
+	value float64,
+	opts *UpdateOptions,
+) (float64, error) {
+	wrapMsg := "unable to update the quota"
+
+	// Determine the effective date of the update.
+	effectiveDate := time.Now()
+	if opts.EffectiveDate != nil {
+		effectiveDate = *opts.EffectiveDate
+	}
+
+	// Determine the new quota value.
+	currentQuotaValue := subscription.GetCurrentQuotaValue(*resourceTypeID)
+	update := model.Update{
+		ValueType:         model.ValueTypeQuotas,
+		Value:             value,
+		EffectiveDate:     effectiveDate,
+		UpdateOperationID: updateOperation.ID,
+		ResourceTypeID:    resourceTypeID,
+		UserID:            subscription.UserID,
+		OrganizationID:    subscription.OrganizationID,
+		Metadata:          opts.Metadata,
+		PreviousValue:     &currentQuotaValue,
+		Actor:             opts.Actor,
+		BatchID:           opts.BatchID,
+	}
+	switch updateOperation.Name {
+	case model.UpdateOperationSet, model.UpdateOperationAdd, model.UpdateOperationSubtract:
+	default:
+		return 0, fmt.Errorf("%s: invalid update type: %s", wrapMsg, updateOperation.Name)
+	}
+	newQuotaValue := update.GetNewValue(updateOperation, currentQuotaValue)
+	update.NewValue = &newQuotaValue
+
+	// Update the quota.
+	quota := &model.Quota{
+		SubscriptionID: subscription.ID,
+		ResourceTypeID: resourceTypeID,
+		Quota:          newQuotaValue,
+	}
+	err := db.UpsertQuota(ctx, tx, quota)
+	if err != nil {
+		return 0, err
+	}
+
+	// Record the update in the database.
+	err = db.SaveUpdate(ctx, tx, &update)
+	if err != nil {
+		return 0, errors.Wrap(err, wrapMsg)
+	}
+
+	return newQuotaValue, nil
+}
@@ -139,6 +139,11 @@ type UpdateCurrentSubscriptionQuotaParameters struct {
 	// in: query
 	ResourceTypeName string `json:"resource-type"`
 
+	// The person or service requesting the quota change, which is recorded with the change.
+	//
+	// in: query
+	Actor string `json:"actor"`
+
 	// in: body
 	Body httpmodel.QuotaValue
 }
@@ -278,6 +283,12 @@ type UpdateSubscriptionParameters struct {
 	// in: query
 	// format: date
 	EndDate string `json:"end_date"`
+
+	// True if consumable quotas and usages should be reset at each yearly anniversary of the start date
+	//
+	// in: query
+	// default: false
+	AllotmentMode bool `json:"allotment-mode"`
 }
 
 // Subscription Details
@@ -310,7 +321,7 @@ type PlansResponseWrapper struct {
 
 // Plan ID
 //
-// swagger:parameters getPlanByID
+// swagger:parameters getPlanByID archivePlan unarchivePlan
 type PlanIDParameter struct {
 
 	// The plan identifier
@@ -344,6 +355,35 @@ type AddPlans struct {
 	Body httpmodel.NewPlan
 }
 
+// Parameters for the endpoint used to list plans.
+//
+// swagger:parameters listPlans
+type ListPlansParameters struct {
+
+	// True if archived plans should be included in the listing
+	//
+	// in: query
+	// default: false
+	IncludeArchived *bool `json:"include-archived"`
+}
+
+// Updated Plan Information
+//
+// swagger:parameters updatePlan
+type UpdatePlanParameters struct {
+
+	// The plan identifier
+	//
+	// in:path
+	// required: true
+	PlanID string `json:"plan_id"`
+
+	// The updated plan details
+	//
+	// in: body
+	Body httpmodel.UpdatedPlan
+}
+
 // Getting the Active Rate for a Plan
 //
 // swagger:parameters getPlanActiveRate
@@ -515,3 +555,970 @@ type UpdateResourceTypeParameters struct {
 	// in: body
 	Body model.ResourceType
 }
+
+// Addons
+
+// Addon Listing
+//
+// swagger:response addonsResponse
+type AddonsResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The list of addons
+		Result []model.Addon `json:"result"`
+	}
+}
+
+// Addon Information
+//
+// swagger:response addonResponse
+type AddonResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The addon information
+		Result model.Addon `json:"result"`
+	}
+}
+
+// Addon ID
+//
+// swagger:parameters getAddonByID deleteAddon getAddonActiveRate
+type AddonIDParameter struct {
+
+	// The addon identifier
+	//
+	// in:path
+	// required: true
+	AddonID string `json:"addon_id"`
+}
+
+// Incoming Addon Information
+//
+// swagger:parameters addAddon
+type AddAddonParameters struct {
+
+	// The addon details
+	//
+	// in: body
+	Body httpmodel.NewAddon
+}
+
+// Updated Addon Information
+//
+// swagger:parameters updateAddon
+type UpdateAddonParameters struct {
+
+	// The addon identifier
+	//
+	// in:path
+	// required: true
+	AddonID string `json:"addon_id"`
+
+	// The updated addon details
+	//
+	// in: body
+	Body httpmodel.UpdatedAddon
+}
+
+// Addon Rate Information
+//
+// swagger:response activeAddonRateResponse
+type ActiveAddonRateResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The addon rate information
+		Result model.AddonRate `json:"result"`
+	}
+}
+
+// Adding Rates to an Addon
+//
+// swagger:parameters addAddonRates
+type AddAddonRatesParameters struct {
+
+	// The addon identifier
+	//
+	// in:path
+	// required: true
+	AddonID string `json:"addon_id"`
+
+	// The addon rates
+	//
+	// in: body
+	Body httpmodel.NewAddonRateList
+}
+
+// Subscription Addons
+
+// Parameters for the endpoint used to apply an addon to a subscription.
+//
+// swagger:parameters addSubscriptionAddon
+type AddSubscriptionAddonParameters struct {
+
+	// The subscription identifier
+	//
+	// in: path
+	// required: true
+	SubscriptionID string `json:"subscription_id"`
+
+	// The person or service applying the addon, which is recorded with the quota change
+	//
+	// in: query
+	Actor string `json:"actor"`
+
+	// The addon to apply to the subscription
+	//
+	// in: body
+	Body httpmodel.NewSubscriptionAddon
+}
+
+// Subscription Addon Information
+//
+// swagger:response subscriptionAddonResponse
+type SubscriptionAddonResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The subscription addon information
+		Result model.SubscriptionAddon `json:"result"`
+	}
+}
+
+// Parameters for the endpoint used to remove an addon from a subscription.
+//
+// swagger:parameters deleteSubscriptionAddon
+type DeleteSubscriptionAddonParameters struct {
+
+	// The subscription identifier
+	//
+	// in: path
+	// required: true
+	SubscriptionID string `json:"subscription_id"`
+
+	// The subscription addon identifier
+	//
+	// in: path
+	// required: true
+	SubscriptionAddonID string `json:"subscription_addon_id"`
+
+	// If `true`, the quota for a non-consumable resource type will be reduced even if it drops below the current usage
+	//
+	// in: query
+	// default: false
+	Force *bool `json:"force"`
+
+	// The person or service removing the addon, which is recorded with the quota change
+	//
+	// in: query
+	Actor string `json:"actor"`
+}
+
+// Subscription Addon Removal Information
+//
+// swagger:response subscriptionAddonRemovalResponse
+type SubscriptionAddonRemovalResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The subscription addon removal information
+		Result model.SubscriptionAddonRemoval `json:"result"`
+	}
+}
+
+// Overages
+
+// Parameters for the endpoint used to list overages for a user.
+//
+// swagger:parameters getUserOverages
+type GetUserOveragesParameters struct {
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+}
+
+// Overage Listing
+//
+// swagger:response overagesResponse
+type OveragesResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The list of overages
+		Result []model.Overage `json:"result"`
+	}
+}
+
+// Overage listing parameters.
+//
+// swagger:parameters listOverages
+type ListOveragesParameters struct {
+
+	// The starting offset for the listing
+	//
+	// in: query
+	Offset int32 `json:"offset"`
+
+	// The maximum number of overages to include in the listing
+	//
+	// in: query
+	Limit int32 `json:"limit"`
+
+	// The sort field to use for the listing
+	//
+	// enum: ["username","resource-type","overage","overage-percentage"]
+	// in: query
+	SortField string `json:"sort-field"`
+
+	// The sort direction to use for the listing
+	//
+	// enum: ["asc","desc"]
+	// in: query
+	SortDir string `json:"sort-dir"`
+
+	// The username substring to search for in the listing
+	//
+	// in: query
+	Search string `json:"search"`
+
+	// The name of the resource type to include in the listing
+	//
+	// in: query
+	ResourceType string `json:"resource-type"`
+
+	// The minimum overage, expressed as a percentage of the quota, to include in the listing
+	//
+	// in: query
+	MinOveragePercentage float64 `json:"min-overage-percentage"`
+}
+
+// Subscription Overage Listing
+//
+// swagger:response subscriptionOverageListing
+type SubscriptionOverageListingWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The overage listing
+		Result model.SubscriptionOverageListing `json:"result"`
+	}
+}
+
+// Quota Checks
+
+// Parameters for the endpoint used to check a quota.
+//
+// swagger:parameters checkQuota
+type CheckQuotaParameters struct {
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The resource type and the amount of the resource that the caller intends to consume
+	//
+	// in: body
+	Body httpmodel.QuotaCheckRequest
+}
+
+// Quota Check Result
+//
+// swagger:response quotaCheckResponse
+type QuotaCheckResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The quota check result
+		Result model.QuotaCheckResult `json:"result"`
+	}
+}
+
+// Reservations
+
+// Parameters for the endpoint used to place a hold on a resource.
+//
+// swagger:parameters addReservation
+type AddReservationParameters struct {
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The resource type and the amount of the resource to hold
+	//
+	// in: body
+	Body httpmodel.NewReservation
+}
+
+// Parameters for endpoints that operate on a single reservation.
+//
+// swagger:parameters getReservation releaseReservation
+type ReservationIDParameter struct {
+
+	// The reservation identifier
+	//
+	// in: path
+	// required: true
+	ReservationID string `json:"reservation_id"`
+}
+
+// Parameters for the endpoint used to commit a reservation.
+//
+// swagger:parameters commitReservation
+type CommitReservationParameters struct {
+
+	// The reservation identifier
+	//
+	// in: path
+	// required: true
+	ReservationID string `json:"reservation_id"`
+
+	// The amount of the resource that was actually consumed
+	//
+	// in: body
+	Body httpmodel.ReservationCommit
+}
+
+// Reservation Information
+//
+// swagger:response reservationResponse
+type ReservationResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The reservation information
+		Result model.Reservation `json:"result"`
+	}
+}
+
+// Usages
+
+// Parameters for the endpoint used to apply multiple usage updates.
+//
+// swagger:parameters addUsagesBatch
+type AddUsagesBatchParameters struct {
+
+	// The usage updates to apply
+	//
+	// in: body
+	Body httpmodel.UsageBatch
+}
+
+// Usage Batch Response
+//
+// swagger:response usageBatchResponse
+type UsageBatchResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The result of each usage update
+		Result []httpmodel.UsageResult `json:"result"`
+	}
+}
+
+// Parameters for the endpoint used to get the usage history for a user.
+//
+// swagger:parameters getUsageHistory
+type GetUsageHistoryParameters struct {
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The first date in the date range, in YYYY-MM-DD format; defaults to 30 days ago
+	//
+	// in: query
+	From string `json:"from"`
+
+	// The last date in the date range, in YYYY-MM-DD format; defaults to the current date
+	//
+	// in: query
+	To string `json:"to"`
+
+	// The name of the resource type to include in the history; all resource types are included by default
+	//
+	// in: query
+	ResourceType string `json:"resource-type"`
+
+	// The interval used to divide the date range
+	//
+	// in: query
+	// enum: ["day","week","month"]
+	// default: day
+	Interval string `json:"interval"`
+}
+
+// Usage History
+//
+// swagger:response usageHistoryResponse
+type UsageHistoryResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The usage history
+		Result model.UsageHistory `json:"result"`
+	}
+}
+
+// Parameters for the endpoint used to rebuild usage values from the usage updates.
+//
+// swagger:parameters rebuildUsages
+type RebuildUsagesParameters struct {
+
+	// The username of the user whose usage values should be rebuilt; all users are checked by default
+	//
+	// in: query
+	Username string `json:"username"`
+
+	// True if the recorded usage values should be replaced with the replayed usage values
+	//
+	// in: query
+	// default: false
+	Apply bool `json:"apply"`
+}
+
+// Usage Rebuild Report
+//
+// swagger:response usageRebuildResponse
+type UsageRebuildResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The usage rebuild report
+		Result model.UsageRebuildReport `json:"result"`
+	}
+}
+
+// Parameters for the endpoint used to list updates.
+//
+// swagger:parameters listUpdates
+type ListUpdatesParameters struct {
+
+	// The number of updates to skip
+	//
+	// in: query
+	// minimum: 0
+	// default: 0
+	Offset int32 `json:"offset"`
+
+	// The maximum number of updates to list
+	//
+	// in: query
+	// minimum: 0
+	// default: 50
+	Limit int32 `json:"limit"`
+
+	// The direction to sort the updates by effective date
+	//
+	// in: query
+	// enum: ["asc","desc"]
+	// default: asc
+	SortDir string `json:"sort-dir"`
+
+	// Only list updates for the user with this username
+	//
+	// in: query
+	Username string `json:"username"`
+
+	// Only list updates of this value type
+	//
+	// in: query
+	// enum: ["quotas","usages"]
+	ValueType string `json:"value-type"`
+
+	// Only list updates for the resource type with this name
+	//
+	// in: query
+	ResourceType string `json:"resource-type"`
+
+	// Only list updates with this key in their metadata
+	//
+	// in: query
+	MetadataKey string `json:"metadata-key"`
+
+	// Only list updates with this value for the metadata key; requires metadata-key
+	//
+	// in: query
+	MetadataValue string `json:"metadata-value"`
+
+	// Only list updates made by the bulk operation with this batch identifier
+	//
+	// in: query
+	BatchID string `json:"batch-id"`
+}
+
+// Update Listing
+//
+// swagger:response updateListingResponse
+type UpdateListingResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The update listing
+		Result model.UpdateListing `json:"result"`
+	}
+}
+
+// Parameters for the endpoint used to forecast usage.
+//
+// swagger:parameters getUsageForecast
+type GetUsageForecastParameters struct {
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The number of days of usage updates to use when computing consumption rates
+	//
+	// in: query
+	// minimum: 1
+	// default: 30
+	Window int32 `json:"window"`
+
+	// The name of the resource type to forecast; all consumable resource types are forecast by default
+	//
+	// in: query
+	ResourceType string `json:"resource-type"`
+}
+
+// Usage Forecast
+//
+// swagger:response usageForecastResponse
+type UsageForecastResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The usage forecast
+		Result model.UsageForecast `json:"result"`
+	}
+}
+
+// Parameters for the endpoint used to get the quota history for a user.
+//
+// swagger:parameters getQuotaHistory
+type GetQuotaHistoryParameters struct {
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The number of quota changes to skip
+	//
+	// in: query
+	// minimum: 0
+	// default: 0
+	Offset int32 `json:"offset"`
+
+	// The maximum number of quota changes to list
+	//
+	// in: query
+	// minimum: 0
+	// default: 50
+	Limit int32 `json:"limit"`
+
+	// The direction to sort the quota changes by effective date
+	//
+	// in: query
+	// enum: ["asc","desc"]
+	// default: asc
+	SortDir string `json:"sort-dir"`
+
+	// Only list changes to the quota for the resource type with this name
+	//
+	// in: query
+	ResourceType string `json:"resource-type"`
+}
+
+// Quota Overrides
+
+// Parameters for the endpoint used to add a quota override.
+//
+// swagger:parameters addQuotaOverride
+type AddQuotaOverrideParameters struct {
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The person or service requesting the quota override, which is recorded with the quota changes
+	//
+	// in: query
+	Actor string `json:"actor"`
+
+	// The quota override to add
+	//
+	// in: body
+	Body httpmodel.NewQuotaOverride
+}
+
+// Parameters for the endpoint used to list quota overrides.
+//
+// swagger:parameters listQuotaOverrides
+type ListQuotaOverridesParameters struct {
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// True if retired quota overrides should be included in the listing
+	//
+	// in: query
+	// default: false
+	IncludeRetired *bool `json:"include-retired"`
+}
+
+// Parameters for the endpoint used to retire a quota override.
+//
+// swagger:parameters retireQuotaOverride
+type RetireQuotaOverrideParameters struct {
+
+	// The quota override identifier
+	//
+	// in: path
+	// required: true
+	OverrideID string `json:"override_id"`
+
+	// The person or service retiring the quota override, which is recorded with the quota change
+	//
+	// in: query
+	Actor string `json:"actor"`
+}
+
+// Quota Override Information
+//
+// swagger:response quotaOverrideResponse
+type QuotaOverrideResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The quota override information
+		Result model.QuotaOverride `json:"result"`
+	}
+}
+
+// Quota Override Listing
+//
+// swagger:response quotaOverrideListingResponse
+type QuotaOverrideListingResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The list of quota overrides
+		Result []model.QuotaOverride `json:"result"`
+	}
+}
+
+// Quota Grants
+
+// Parameters for the endpoint used to grant quotas to multiple users.
+//
+// swagger:parameters grantQuotas
+type GrantQuotasParameters struct {
+
+	// The person or service requesting the grant, which is recorded with each quota change
+	//
+	// in: query
+	Actor string `json:"actor"`
+
+	// The users, resource type, amount, and mode of the grant
+	//
+	// in: body
+	Body httpmodel.QuotaGrant
+}
+
+// Quota Grant Results
+//
+// swagger:response quotaGrantResponse
+type QuotaGrantResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The results of the grant
+		Result httpmodel.QuotaGrantReport `json:"result"`
+	}
+}
+
+// Allotment Periods
+
+// Parameters for the endpoint used to list the allotment periods of a subscription.
+//
+// swagger:parameters listSubscriptionPeriods
+type ListSubscriptionPeriodsParameters struct {
+
+	// The subscription identifier
+	//
+	// in: path
+	// required: true
+	SubscriptionID string `json:"subscription_id"`
+}
+
+// Subscription Period Listing
+//
+// swagger:response subscriptionPeriodListingResponse
+type SubscriptionPeriodListingResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The list of completed allotment periods
+		Result []model.SubscriptionPeriod `json:"result"`
+	}
+}
+
+// Organizations
+
+// Parameters for the endpoint used to add an organization.
+//
+// swagger:parameters addOrganization
+type AddOrganizationParameters struct {
+
+	// The organization to add
+	//
+	// in: body
+	Body httpmodel.NewOrganization
+}
+
+// Parameters for the endpoint used to get the details of an organization.
+//
+// swagger:parameters getOrganization
+type GetOrganizationParameters struct {
+
+	// The organization identifier
+	//
+	// in: path
+	// required: true
+	OrganizationID string `json:"organization_id"`
+}
+
+// Parameters for the endpoint used to add a user to an organization.
+//
+// swagger:parameters putOrganizationMember
+type PutOrganizationMemberParameters struct {
+
+	// The organization identifier
+	//
+	// in: path
+	// required: true
+	OrganizationID string `json:"organization_id"`
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The member's role
+	//
+	// in: body
+	Body httpmodel.OrganizationMembership
+}
+
+// Parameters for the endpoint used to remove a user from an organization.
+//
+// swagger:parameters deleteOrganizationMember
+type DeleteOrganizationMemberParameters struct {
+
+	// The organization identifier
+	//
+	// in: path
+	// required: true
+	OrganizationID string `json:"organization_id"`
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+}
+
+// Parameters for the endpoint used to set an organization member cap.
+//
+// swagger:parameters putOrganizationMemberCap
+type PutOrganizationMemberCapParameters struct {
+
+	// The organization identifier
+	//
+	// in: path
+	// required: true
+	OrganizationID string `json:"organization_id"`
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The name of the resource type
+	//
+	// in: path
+	// required: true
+	ResourceType string `json:"resource-type"`
+
+	// The member's cap
+	//
+	// in: body
+	Body httpmodel.OrganizationMemberCap
+}
+
+// Parameters for the endpoint used to remove an organization member cap.
+//
+// swagger:parameters deleteOrganizationMemberCap
+type DeleteOrganizationMemberCapParameters struct {
+
+	// The organization identifier
+	//
+	// in: path
+	// required: true
+	OrganizationID string `json:"organization_id"`
+
+	// The username
+	//
+	// in: path
+	// required: true
+	Username string `json:"username"`
+
+	// The name of the resource type
+	//
+	// in: path
+	// required: true
+	ResourceType string `json:"resource-type"`
+}
+
+// Parameters for the endpoint used to subscribe an organization to a plan.
+//
+// swagger:parameters addOrganizationSubscription
+type AddOrganizationSubscriptionParameters struct {
+
+	// The organization identifier
+	//
+	// in: path
+	// required: true
+	OrganizationID string `json:"organization_id"`
+
+	// The plan name and subscription options
+	//
+	// in: body
+	Body httpmodel.NewOrganizationSubscription
+}
+
+// Parameters for the endpoint used to get an organization's active subscription.
+//
+// swagger:parameters getOrganizationSubscription
+type GetOrganizationSubscriptionParameters struct {
+
+	// The organization identifier
+	//
+	// in: path
+	// required: true
+	OrganizationID string `json:"organization_id"`
+}
+
+// Organization Listing
+//
+// swagger:response organizationListingResponse
+type OrganizationListingResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The list of organizations
+		Result []model.Organization `json:"result"`
+	}
+}
+
+// Organization Information
+//
+// swagger:response organizationResponse
+type OrganizationResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The organization information
+		Result model.Organization `json:"result"`
+	}
+}
+
+// Organization Member Information
+//
+// swagger:response organizationMemberResponse
+type OrganizationMemberResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The organization member information
+		Result model.OrganizationMember `json:"result"`
+	}
+}
+
+// Organization Subscription Information
+//
+// swagger:response organizationSubscriptionResponse
+type OrganizationSubscriptionResponseWrapper struct {
+
+	// in: body
+	Body struct {
+		ResponseBodyWrapper
+
+		// The organization's active subscription along with the amounts consumed by each member
+		Result model.OrganizationSubscription `json:"result"`
+	}
+}
@@ -87,6 +87,19 @@ func main() {
 		}
 	}
 
+	// Run the subcommand if one was specified.
+	switch flag.Arg(0) {
+	case "":
+	case "rebuild-usages":
+		err = rebuildUsages(spec, flag.Args()[1:])
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	default:
+		log.Fatalf("unrecognized subcommand: %s", flag.Arg(0))
+	}
+
 	// Initialize the server.
 	server.Init(spec)
 }
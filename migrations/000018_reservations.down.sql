@@ -0,0 +1,7 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+DROP TABLE IF EXISTS reservations;
+
+COMMIT;
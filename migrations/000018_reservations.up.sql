@@ -0,0 +1,42 @@
+--
+-- Adds a table that tracks resource reservations (holds) placed against subscriptions.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    subscription_id uuid NOT NULL,
+    resource_type_id uuid NOT NULL,
+    amount numeric NOT NULL CHECK (amount >= 0),
+    committed_amount numeric,
+    status text NOT NULL DEFAULT 'held'
+        CHECK (status IN ('held', 'committed', 'released', 'expired')),
+    expires_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_modified_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (subscription_id) REFERENCES subscriptions(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_type_id) REFERENCES resource_types(id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+--
+-- Outstanding holds are looked up frequently, so they get their own index.
+--
+CREATE INDEX IF NOT EXISTS reservations_held_index
+    ON reservations (subscription_id, resource_type_id, expires_at)
+    WHERE status = 'held';
+
+--
+-- A trigger to set the last_modified_at field when a row is modified in the reservations table.
+--
+DROP TRIGGER IF EXISTS reservations_last_modified_at_trigger ON reservations CASCADE;
+CREATE TRIGGER reservations_last_modified_at_trigger
+    BEFORE UPDATE ON reservations
+    FOR EACH ROW
+    EXECUTE PROCEDURE moddatetime(last_modified_at);
+
+COMMIT;
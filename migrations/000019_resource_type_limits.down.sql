@@ -0,0 +1,8 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS resource_types DROP IF EXISTS grace_percentage CASCADE;
+ALTER TABLE IF EXISTS resource_types DROP IF EXISTS warning_percentage CASCADE;
+
+COMMIT;
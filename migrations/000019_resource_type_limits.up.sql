@@ -0,0 +1,23 @@
+--
+-- Adds soft and hard limit settings to resource types.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+--
+-- The percentage of the quota at which users should be warned that they're approaching the quota.
+--
+ALTER TABLE IF EXISTS resource_types ADD IF NOT EXISTS warning_percentage numeric NOT NULL DEFAULT 90
+    CHECK (warning_percentage > 0 AND warning_percentage <= 100);
+
+--
+-- The percentage of the quota that users may exceed the quota by before they're blocked.
+--
+ALTER TABLE IF EXISTS resource_types ADD IF NOT EXISTS grace_percentage numeric NOT NULL DEFAULT 0
+    CHECK (grace_percentage >= 0);
+
+UPDATE resource_types SET grace_percentage = 10 WHERE "name" = 'data.size';
+
+COMMIT;
@@ -0,0 +1,27 @@
+--
+-- Adds a transactional outbox for events that need to be delivered to other services.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    event_type text NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at timestamp with time zone,
+    PRIMARY KEY (id)
+);
+
+--
+-- Events that haven't been dispatched yet are looked up frequently, so they get their own index.
+--
+CREATE INDEX IF NOT EXISTS outbox_events_pending_index
+    ON outbox_events (created_at)
+    WHERE dispatched_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+DROP INDEX IF EXISTS updates_idempotency_key_index;
+ALTER TABLE IF EXISTS updates DROP COLUMN IF EXISTS request_hash;
+ALTER TABLE IF EXISTS updates DROP COLUMN IF EXISTS idempotency_key;
+
+COMMIT;
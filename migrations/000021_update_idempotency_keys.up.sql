@@ -0,0 +1,19 @@
+--
+-- Adds idempotency keys to updates so that clients can safely retry usage updates.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS updates ADD COLUMN IF NOT EXISTS idempotency_key text;
+ALTER TABLE IF EXISTS updates ADD COLUMN IF NOT EXISTS request_hash text;
+
+--
+-- Each idempotency key may only be used once.
+--
+CREATE UNIQUE INDEX IF NOT EXISTS updates_idempotency_key_index
+    ON updates (idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
+
+COMMIT;
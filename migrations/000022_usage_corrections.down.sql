@@ -0,0 +1,28 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+--
+-- The SUBTRACT and REVERSE update operations can't be removed while updates refer to them, and deleting those updates
+-- would silently change the ledger, so refuse to continue instead.
+--
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM updates u
+        JOIN update_operations o ON u.update_operation_id = o.id
+        WHERE o.name IN ('SUBTRACT', 'REVERSE')
+    ) THEN
+        RAISE EXCEPTION 'SUBTRACT or REVERSE updates have been recorded; '
+            'delete or convert them before reverting this migration';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS updates_original_update_id_index;
+ALTER TABLE IF EXISTS updates DROP COLUMN IF EXISTS previous_value;
+ALTER TABLE IF EXISTS updates DROP COLUMN IF EXISTS original_update_id;
+
+DELETE FROM update_operations WHERE name IN ('SUBTRACT', 'REVERSE');
+
+COMMIT;
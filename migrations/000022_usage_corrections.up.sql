@@ -0,0 +1,29 @@
+--
+-- Adds the update operations and columns required to support usage corrections.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+INSERT INTO update_operations (id, name) VALUES
+    ('6a5b8f3c-2d0e-4c1f-9b7a-3e8d1c2f4a60', 'SUBTRACT'),
+    ('6a5b8f3c-2d0e-4c1f-9b7a-3e8d1c2f4a61', 'REVERSE')
+    ON CONFLICT DO NOTHING;
+
+--
+-- The update that a correction applies to.
+--
+ALTER TABLE IF EXISTS updates ADD COLUMN IF NOT EXISTS original_update_id uuid
+    REFERENCES updates(id) ON DELETE SET NULL;
+
+--
+-- The value before the update was applied, which is required to reverse SET and SUBTRACT updates.
+--
+ALTER TABLE IF EXISTS updates ADD COLUMN IF NOT EXISTS previous_value numeric;
+
+CREATE INDEX IF NOT EXISTS updates_original_update_id_index
+    ON updates (original_update_id)
+    WHERE original_update_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS updates DROP CONSTRAINT IF EXISTS updates_metadata_object_check;
+
+ALTER TABLE IF EXISTS updates ALTER COLUMN metadata TYPE text USING metadata::text;
+
+COMMIT;
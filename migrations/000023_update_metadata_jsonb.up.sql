@@ -0,0 +1,39 @@
+--
+-- Converts the metadata recorded with updates to JSONB so that updates can be found by metadata key and value.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+--
+-- Converts a metadata value to a JSON object. Metadata that is already a JSON object is stored as is. Any other
+-- non-empty metadata is stored in the `metadata` field of a new JSON object.
+--
+CREATE OR REPLACE FUNCTION pg_temp.metadata_to_jsonb(metadata text) RETURNS jsonb AS $$
+DECLARE
+    parsed jsonb;
+BEGIN
+    IF metadata IS NULL OR btrim(metadata) = '' THEN
+        RETURN NULL;
+    END IF;
+
+    BEGIN
+        parsed := metadata::jsonb;
+    EXCEPTION WHEN invalid_text_representation THEN
+        RETURN jsonb_build_object('metadata', metadata);
+    END;
+
+    IF jsonb_typeof(parsed) = 'object' THEN
+        RETURN parsed;
+    END IF;
+    RETURN jsonb_build_object('metadata', metadata);
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE IF EXISTS updates ALTER COLUMN metadata TYPE jsonb USING pg_temp.metadata_to_jsonb(metadata);
+
+ALTER TABLE IF EXISTS updates ADD CONSTRAINT updates_metadata_object_check
+    CHECK (metadata IS NULL OR jsonb_typeof(metadata) = 'object');
+
+COMMIT;
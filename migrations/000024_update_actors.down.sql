@@ -0,0 +1,10 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+DROP INDEX IF EXISTS updates_user_id_value_type_effective_date_index;
+
+ALTER TABLE IF EXISTS updates DROP COLUMN IF EXISTS new_value;
+ALTER TABLE IF EXISTS updates DROP COLUMN IF EXISTS actor;
+
+COMMIT;
@@ -0,0 +1,22 @@
+--
+-- Adds the columns required to audit quota changes.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+--
+-- The person or service that requested the update, if known.
+--
+ALTER TABLE IF EXISTS updates ADD COLUMN IF NOT EXISTS actor text;
+
+--
+-- The value after the update was applied.
+--
+ALTER TABLE IF EXISTS updates ADD COLUMN IF NOT EXISTS new_value numeric;
+
+CREATE INDEX IF NOT EXISTS updates_user_id_value_type_effective_date_index
+    ON updates (user_id, value_type, effective_date);
+
+COMMIT;
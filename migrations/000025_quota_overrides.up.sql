@@ -0,0 +1,49 @@
+--
+-- Adds a table that tracks temporary increases to quotas.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+CREATE TABLE IF NOT EXISTS quota_overrides (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    subscription_id uuid NOT NULL,
+    resource_type_id uuid NOT NULL,
+    amount numeric NOT NULL CHECK (amount > 0),
+    reason text NOT NULL,
+    status text NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'active', 'retired')),
+    effective_start_date timestamp with time zone NOT NULL,
+    effective_end_date timestamp with time zone NOT NULL,
+    actor text,
+    retired_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_modified_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK (effective_start_date < effective_end_date),
+    FOREIGN KEY (subscription_id) REFERENCES subscriptions(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_type_id) REFERENCES resource_types(id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+--
+-- Overrides that haven't been retired yet are checked periodically, so they get their own index.
+--
+CREATE INDEX IF NOT EXISTS quota_overrides_unretired_index
+    ON quota_overrides (effective_start_date, effective_end_date)
+    WHERE status != 'retired';
+
+CREATE INDEX IF NOT EXISTS quota_overrides_subscription_id_index
+    ON quota_overrides (subscription_id, resource_type_id);
+
+--
+-- A trigger to set the last_modified_at field when a row is modified in the quota_overrides table.
+--
+DROP TRIGGER IF EXISTS quota_overrides_last_modified_at_trigger ON quota_overrides CASCADE;
+CREATE TRIGGER quota_overrides_last_modified_at_trigger
+    BEFORE UPDATE ON quota_overrides
+    FOR EACH ROW
+    EXECUTE PROCEDURE moddatetime(last_modified_at);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+DROP INDEX IF EXISTS updates_batch_id_index;
+
+ALTER TABLE IF EXISTS updates DROP COLUMN IF EXISTS batch_id;
+
+COMMIT;
@@ -0,0 +1,13 @@
+--
+-- Adds a column used to group updates that were made by a single bulk operation.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS updates ADD COLUMN IF NOT EXISTS batch_id uuid;
+
+CREATE INDEX IF NOT EXISTS updates_batch_id_index ON updates (batch_id) WHERE batch_id IS NOT NULL;
+
+COMMIT;
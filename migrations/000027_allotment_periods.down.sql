@@ -0,0 +1,14 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+DROP TABLE IF EXISTS subscription_periods;
+DROP TABLE IF EXISTS subscription_allotments;
+
+DROP INDEX IF EXISTS subscriptions_current_period_end_index;
+
+ALTER TABLE IF EXISTS subscriptions DROP COLUMN IF EXISTS current_period_end;
+ALTER TABLE IF EXISTS subscriptions DROP COLUMN IF EXISTS current_period_start;
+ALTER TABLE IF EXISTS subscriptions DROP COLUMN IF EXISTS allotment_mode;
+
+COMMIT;
@@ -0,0 +1,56 @@
+--
+-- Makes the database changes required to support per-period allotments in multi-year subscriptions.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+--
+-- Subscriptions in allotment mode receive a fresh allotment of each consumable resource at each yearly anniversary of
+-- the effective start date, rather than receiving all of their consumable resources up front.
+--
+ALTER TABLE IF EXISTS subscriptions ADD COLUMN IF NOT EXISTS allotment_mode boolean NOT NULL DEFAULT FALSE;
+ALTER TABLE IF EXISTS subscriptions ADD COLUMN IF NOT EXISTS current_period_start timestamp with time zone;
+ALTER TABLE IF EXISTS subscriptions ADD COLUMN IF NOT EXISTS current_period_end timestamp with time zone;
+
+CREATE INDEX IF NOT EXISTS subscriptions_current_period_end_index
+    ON subscriptions (current_period_end)
+    WHERE allotment_mode;
+
+--
+-- The amount of each consumable resource that a subscription in allotment mode receives in each period.
+--
+CREATE TABLE IF NOT EXISTS subscription_allotments (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    subscription_id uuid NOT NULL,
+    resource_type_id uuid NOT NULL,
+    amount numeric NOT NULL CHECK (amount >= 0),
+
+    FOREIGN KEY (subscription_id) REFERENCES subscriptions(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_type_id) REFERENCES resource_types(id) ON DELETE CASCADE,
+    UNIQUE (subscription_id, resource_type_id),
+    PRIMARY KEY (id)
+);
+
+--
+-- The final quota and usage values of each consumable resource in each completed period of a subscription in allotment
+-- mode.
+--
+CREATE TABLE IF NOT EXISTS subscription_periods (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    subscription_id uuid NOT NULL,
+    resource_type_id uuid NOT NULL,
+    period_start timestamp with time zone NOT NULL,
+    period_end timestamp with time zone NOT NULL,
+    quota numeric NOT NULL,
+    usage numeric NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (subscription_id) REFERENCES subscriptions(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_type_id) REFERENCES resource_types(id) ON DELETE CASCADE,
+    UNIQUE (subscription_id, resource_type_id, period_start),
+    PRIMARY KEY (id)
+);
+
+COMMIT;
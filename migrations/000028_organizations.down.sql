@@ -0,0 +1,22 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS reservations DROP COLUMN IF EXISTS user_id;
+
+DROP INDEX IF EXISTS updates_organization_id_index;
+ALTER TABLE IF EXISTS updates DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organization_member_usages;
+
+DELETE FROM subscriptions WHERE organization_id IS NOT NULL;
+DROP INDEX IF EXISTS subscriptions_organization_id_index;
+ALTER TABLE IF EXISTS subscriptions DROP CONSTRAINT IF EXISTS subscriptions_owner_check;
+ALTER TABLE IF EXISTS subscriptions DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE IF EXISTS subscriptions ALTER COLUMN user_id SET NOT NULL;
+
+DROP TABLE IF EXISTS organization_member_caps;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
+
+COMMIT;
@@ -0,0 +1,117 @@
+--
+-- Adds organizations, which allow groups of users to share the resources provided by a single subscription.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    name text NOT NULL UNIQUE,
+    description text NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_modified_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id)
+);
+
+DROP TRIGGER IF EXISTS organizations_last_modified_at_trigger ON organizations CASCADE;
+CREATE TRIGGER organizations_last_modified_at_trigger
+    BEFORE UPDATE ON organizations
+    FOR EACH ROW
+    EXECUTE PROCEDURE moddatetime(last_modified_at);
+
+--
+-- A user may belong to at most one organization, so that there's never any doubt about which pool the user's usage is
+-- charged against.
+--
+CREATE TABLE IF NOT EXISTS organization_members (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    organization_id uuid NOT NULL,
+    user_id uuid NOT NULL UNIQUE,
+    role text NOT NULL DEFAULT 'member'
+        CHECK (role IN ('owner', 'admin', 'member')),
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_members_organization_id_index ON organization_members (organization_id);
+
+--
+-- Optional limits on the amount of a resource that a single member may consume from the organization's pool.
+--
+CREATE TABLE IF NOT EXISTS organization_member_caps (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    organization_member_id uuid NOT NULL,
+    resource_type_id uuid NOT NULL,
+    cap numeric NOT NULL CHECK (cap >= 0),
+
+    FOREIGN KEY (organization_member_id) REFERENCES organization_members(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_type_id) REFERENCES resource_types(id) ON DELETE CASCADE,
+    UNIQUE (organization_member_id, resource_type_id),
+    PRIMARY KEY (id)
+);
+
+--
+-- Subscriptions may be owned by either a user or an organization.
+--
+ALTER TABLE IF EXISTS subscriptions ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE IF EXISTS subscriptions ADD COLUMN IF NOT EXISTS organization_id uuid
+    REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE IF EXISTS subscriptions ADD CONSTRAINT subscriptions_owner_check
+    CHECK ((user_id IS NULL) != (organization_id IS NULL));
+
+CREATE INDEX IF NOT EXISTS subscriptions_organization_id_index
+    ON subscriptions (organization_id)
+    WHERE organization_id IS NOT NULL;
+
+--
+-- The amount of each resource that each member has consumed from an organization's pool.
+--
+CREATE TABLE IF NOT EXISTS organization_member_usages (
+    id uuid NOT NULL DEFAULT uuid_generate_v4(),
+    subscription_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    resource_type_id uuid NOT NULL,
+    usage numeric NOT NULL DEFAULT 0,
+    last_modified_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (subscription_id) REFERENCES subscriptions(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (resource_type_id) REFERENCES resource_types(id) ON DELETE CASCADE,
+    UNIQUE (subscription_id, user_id, resource_type_id),
+    PRIMARY KEY (id)
+);
+
+DROP TRIGGER IF EXISTS organization_member_usages_last_modified_at_trigger ON organization_member_usages CASCADE;
+CREATE TRIGGER organization_member_usages_last_modified_at_trigger
+    BEFORE UPDATE ON organization_member_usages
+    FOR EACH ROW
+    EXECUTE PROCEDURE moddatetime(last_modified_at);
+
+--
+-- Updates to an organization's pool record the organization, and usage updates also record the member.
+--
+ALTER TABLE IF EXISTS updates ADD COLUMN IF NOT EXISTS organization_id uuid
+    REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS updates_organization_id_index
+    ON updates (organization_id)
+    WHERE organization_id IS NOT NULL;
+
+--
+-- Reservations record the user that placed the hold so that holds against an organization's pool can be attributed to
+-- members.
+--
+ALTER TABLE IF EXISTS reservations ADD COLUMN IF NOT EXISTS user_id uuid
+    REFERENCES users(id) ON DELETE SET NULL;
+
+UPDATE reservations SET user_id = subscriptions.user_id
+    FROM subscriptions
+    WHERE reservations.subscription_id = subscriptions.id;
+
+COMMIT;
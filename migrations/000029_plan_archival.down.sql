@@ -0,0 +1,7 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS plans DROP COLUMN IF EXISTS archived;
+
+COMMIT;
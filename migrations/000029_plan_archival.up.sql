@@ -0,0 +1,11 @@
+--
+-- Adds a column used to retire subscription plans without affecting existing subscriptions.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS plans ADD COLUMN IF NOT EXISTS archived boolean NOT NULL DEFAULT false;
+
+COMMIT;
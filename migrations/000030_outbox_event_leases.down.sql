@@ -0,0 +1,7 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS outbox_events DROP COLUMN IF EXISTS claimed_until;
+
+COMMIT;
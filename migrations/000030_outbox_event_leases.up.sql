@@ -0,0 +1,11 @@
+--
+-- Adds a column used to claim outbox events for delivery without holding row locks while they're being delivered.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+ALTER TABLE IF EXISTS outbox_events ADD COLUMN IF NOT EXISTS claimed_until timestamp with time zone;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+DROP INDEX IF EXISTS updates_metadata_index;
+
+COMMIT;
@@ -0,0 +1,11 @@
+--
+-- Adds an index used to find updates by metadata key and value.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+CREATE INDEX IF NOT EXISTS updates_metadata_index ON updates USING gin (metadata jsonb_path_ops);
+
+COMMIT;
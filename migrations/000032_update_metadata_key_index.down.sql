@@ -0,0 +1,8 @@
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+DROP INDEX IF EXISTS updates_metadata_index;
+CREATE INDEX IF NOT EXISTS updates_metadata_index ON updates USING gin (metadata jsonb_path_ops);
+
+COMMIT;
@@ -0,0 +1,12 @@
+--
+-- Replaces the update metadata index with one that supports searching for metadata keys as well as containment.
+--
+
+BEGIN;
+
+SET search_path = public, pg_catalog;
+
+DROP INDEX IF EXISTS updates_metadata_index;
+CREATE INDEX IF NOT EXISTS updates_metadata_index ON updates USING gin (metadata jsonb_ops);
+
+COMMIT;
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/cyverse/qms/config"
+	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/ledger"
+	"github.com/pkg/errors"
+)
+
+// rebuildUsages implements the rebuild-usages subcommand, which replays the recorded usage updates and writes a report
+// of any recorded usage values that don't agree with the replayed values to standard output.
+func rebuildUsages(spec *config.Specification, args []string) error {
+	wrapMsg := "unable to rebuild usages"
+	var err error
+
+	// Parse the command-line arguments.
+	flags := flag.NewFlagSet("rebuild-usages", flag.ExitOnError)
+	username := flags.String("username", "", "The user whose usages should be rebuilt; all users are checked by default")
+	apply := flags.Bool("apply", false, "Replace the recorded usage values with the replayed values")
+	err = flags.Parse(args)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+
+	// Establish the database connection.
+	sqldb, gormdb, err := db.Init("postgres", spec.DatabaseURI)
+	if err != nil {
+		return errors.Wrap(err, wrapMsg)
+	}
+	defer sqldb.Close()
+
+	// Rebuild the usages.
+	ctx := context.Background()
+	report, err := ledger.RebuildUsages(ctx, gormdb, strings.TrimSuffix(*username, spec.UsernameSuffix), *apply)
+	if err != nil {
+		return err
+	}
+
+	// Write the report.
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
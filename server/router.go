@@ -58,6 +58,27 @@ func registerUserEndpoints(users *echo.Group, s *controllers.Server) {
 	users.PUT("/:username/:plan_name", s.UpdateSubscription)
 
 	users.GET("/:username/subscriptions", s.ListUserSubscriptions)
+
+	// Lists the changes made to the user's quotas.
+	users.GET("/:username/quota-history", s.GetQuotaHistory)
+
+	// Temporarily increases a quota in the user's subscription.
+	users.POST("/:username/quota-overrides", s.AddQuotaOverride)
+
+	// Lists the temporary quota increases in the user's subscriptions.
+	users.GET("/:username/quota-overrides", s.ListQuotaOverrides)
+
+	// Lists the resource types for which the user's usage is at or above the quota.
+	users.GET("/:username/overages", s.GetUserOverages)
+
+	// Determines whether or not the user may consume the requested amount of a resource.
+	users.POST("/:username/check", s.CheckQuota)
+
+	// Projects when the user will run out of each consumable resource.
+	users.GET("/:username/forecast", s.GetUsageForecast)
+
+	// Places a hold on part of the user's remaining allowance for a resource type.
+	users.POST("/:username/reservations", s.AddReservation)
 }
 
 func registerPlanEndpoints(plans *echo.Group, s *controllers.Server) {
@@ -70,6 +91,15 @@ func registerPlanEndpoints(plans *echo.Group, s *controllers.Server) {
 	// Gets the details of a plan by its UUID.
 	plans.GET("/:plan_id", s.GetPlanByID)
 
+	// Updates the name and description of an existing plan.
+	plans.PUT("/:plan_id", s.UpdatePlan)
+
+	// Archives a plan so that no new subscriptions to it can be created.
+	plans.POST("/:plan_id/archive", s.ArchivePlan)
+
+	// Restores an archived plan.
+	plans.POST("/:plan_id/unarchive", s.UnarchivePlan)
+
 	// Reports the active rate for a plan.
 	plans.GET("/:plan_id/active-rate", s.GetActivePlanRate)
 
@@ -97,6 +127,58 @@ func registerResourceTypeEndpoints(resourceTypes *echo.Group, s *controllers.Ser
 	resourceTypes.PUT("/:resource_type_id", s.UpdateResourceType)
 }
 
+func registerAddonEndpoints(addons *echo.Group, s *controllers.Server) {
+	// Lists the available addons.
+	addons.GET("", s.ListAddons)
+
+	// Adds a new addon to the database.
+	addons.POST("", s.AddAddon)
+
+	// Gets the details of an addon by its UUID.
+	addons.GET("/:addon_id", s.GetAddonByID)
+
+	// Updates an existing addon.
+	addons.PUT("/:addon_id", s.UpdateAddon)
+
+	// Removes an addon from the database.
+	addons.DELETE("/:addon_id", s.DeleteAddon)
+
+	// Reports the active rate for an addon.
+	addons.GET("/:addon_id/active-rate", s.GetActiveAddonRate)
+
+	// Adds rates to an existing addon.
+	addons.POST("/:addon_id/rates", s.AddAddonRates)
+}
+
+func registerOrganizationEndpoints(organizations *echo.Group, s *controllers.Server) {
+	// Lists the organizations.
+	organizations.GET("", s.ListOrganizations)
+
+	// Adds a new organization.
+	organizations.POST("", s.AddOrganization)
+
+	// Gets the details of an organization.
+	organizations.GET("/:organization_id", s.GetOrganization)
+
+	// Adds a user to an organization or changes the user's role.
+	organizations.PUT("/:organization_id/members/:username", s.PutOrganizationMember)
+
+	// Removes a user from an organization.
+	organizations.DELETE("/:organization_id/members/:username", s.DeleteOrganizationMember)
+
+	// Limits the amount of a resource that a member may consume from the organization's pool.
+	organizations.PUT("/:organization_id/members/:username/caps/:resource-type", s.PutOrganizationMemberCap)
+
+	// Removes the limit on the amount of a resource that a member may consume.
+	organizations.DELETE("/:organization_id/members/:username/caps/:resource-type", s.DeleteOrganizationMemberCap)
+
+	// Subscribes an organization to a plan.
+	organizations.POST("/:organization_id/subscriptions", s.AddOrganizationSubscription)
+
+	// Gets an organization's active subscription along with the amounts consumed by each member.
+	organizations.GET("/:organization_id/subscription", s.GetOrganizationSubscription)
+}
+
 func RegisterHandlers(s controllers.Server) {
 
 	// The base URL acts as a health check endpoint.
@@ -114,11 +196,25 @@ func RegisterHandlers(s controllers.Server) {
 	subscriptions.POST("/", s.AddSubscriptions)
 	subscriptions.GET("", s.ListSubscriptions)
 	subscriptions.GET("/", s.ListSubscriptions)
+	subscriptions.POST("/:subscription_id/addons", s.AddSubscriptionAddon)
+	subscriptions.DELETE("/:subscription_id/addons/:subscription_addon_id", s.DeleteSubscriptionAddon)
+	subscriptions.GET("/:subscription_id/periods", s.ListSubscriptionPeriods)
 
 	usages := v1.Group("/usages")
 	usages.GET("/:username", s.GetAllUsageOfUser)
 	usages.POST("", s.AddUsages)
+	usages.POST("/batch", s.AddUsagesBatch)
+	usages.POST("/rebuild", s.RebuildUsages)
 	usages.GET("/:username/updates", s.GetAllUsageUpdatesForUser)
+	usages.GET("/:username/history", s.GetUsageHistory)
+
+	updates := v1.Group("/updates")
+	updates.GET("", s.ListUpdates)
+	updates.GET("/", s.ListUpdates)
+
+	overages := v1.Group("/overages")
+	overages.GET("", s.ListOverages)
+	overages.GET("/", s.ListOverages)
 
 	users := v1.Group("/users")
 	registerUserEndpoints(users, &s)
@@ -126,4 +222,22 @@ func RegisterHandlers(s controllers.Server) {
 	resourceTypes := v1.Group("/resource-types")
 	registerResourceTypeEndpoints(resourceTypes, &s)
 
+	addons := v1.Group("/addons")
+	registerAddonEndpoints(addons, &s)
+
+	reservations := v1.Group("/reservations")
+	reservations.GET("/:reservation_id", s.GetReservation)
+	reservations.POST("/:reservation_id/commit", s.CommitReservation)
+	reservations.DELETE("/:reservation_id", s.ReleaseReservation)
+
+	quotaGrants := v1.Group("/quota-grants")
+	quotaGrants.POST("", s.GrantQuotas)
+	quotaGrants.POST("/", s.GrantQuotas)
+
+	quotaOverrides := v1.Group("/quota-overrides")
+	quotaOverrides.DELETE("/:override_id", s.RetireQuotaOverride)
+
+	organizations := v1.Group("/organizations")
+	registerOrganizationEndpoints(organizations, &s)
+
 }
@@ -1,11 +1,14 @@
 package server
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/cyverse/qms/config"
 	"github.com/cyverse/qms/internal/controllers"
 	"github.com/cyverse/qms/internal/db"
+	"github.com/cyverse/qms/internal/jobs"
+	"github.com/cyverse/qms/internal/outbox"
 	"github.com/cyverse/qms/logging"
 	"github.com/sirupsen/logrus"
 )
@@ -25,18 +28,40 @@ func Init(spec *config.Specification) {
 	}
 
 	s := controllers.Server{
-		Router:         e,
-		DB:             db,
-		GORMDB:         gormdb,
-		Service:        "qms",
-		Title:          "serviceInfo.Title",   //TODO: correct this
-		Version:        "serviceInfo.Version", //TODO:correct this
-		UsernameSuffix: spec.UsernameSuffix,
+		Router:              e,
+		DB:                  db,
+		GORMDB:              gormdb,
+		Service:             "qms",
+		Title:               "serviceInfo.Title",   //TODO: correct this
+		Version:             "serviceInfo.Version", //TODO:correct this
+		UsernameSuffix:      spec.UsernameSuffix,
+		ReportOverages:      spec.ReportOverages,
+		ReservationLifetime: spec.ReservationLifetime,
+		UsageBatchLimit:     spec.UsageBatchLimit,
 	}
 
 	// Register the handlers.
 	RegisterHandlers(s)
 
+	// Create the sink that outbox events are delivered to.
+	sink, err := outbox.NewSink(&outbox.SinkSettings{
+		Type:       spec.OutboxSink,
+		WebhookURL: spec.OutboxWebhookURL,
+		LogPath:    spec.OutboxLogPath,
+	})
+	if err != nil {
+		log.Fatalf("service initialization failed: %s", err.Error())
+	}
+
+	// Start the background jobs.
+	jobs.Start(
+		context.Background(),
+		jobs.ExpireReservations(gormdb),
+		jobs.ProcessQuotaOverrides(gormdb),
+		jobs.RolloverAllotmentPeriods(gormdb),
+		jobs.DispatchOutboxEvents(outbox.NewDispatcher(gormdb, sink), spec.OutboxInterval),
+	)
+
 	log.Info("starting the service")
 	log.Fatal(e.Start(fmt.Sprintf(":%d", 9000)))
 }